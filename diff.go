@@ -0,0 +1,221 @@
+package xiter
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+)
+
+// EditOp is the kind of operation performed by an [Edit].
+type EditOp int
+
+const (
+	// EditEqual leaves an element that is in both sequences alone.
+	EditEqual EditOp = iota
+
+	// EditDelete removes an element of the first sequence.
+	EditDelete
+
+	// EditInsert adds an element of the second sequence.
+	EditInsert
+)
+
+func (op EditOp) String() string {
+	switch op {
+	case EditEqual:
+		return " "
+	case EditDelete:
+		return "-"
+	case EditInsert:
+		return "+"
+	default:
+		return fmt.Sprintf("EditOp(%d)", int(op))
+	}
+}
+
+// Edit is a single step of the transformation of one sequence into
+// another as yielded by [Diff].
+type Edit[T any] struct {
+	Op EditOp
+
+	// A and B are the indices in the first and second sequences,
+	// respectively, at which the edit takes place. For an insertion,
+	// A is the index in the first sequence that the element is
+	// inserted before, and, for a deletion, B is the index in the
+	// second sequence that the element would have been before.
+	A, B int
+
+	// V is the element being kept, deleted or inserted.
+	V T
+}
+
+// Diff returns a Seq over the edits that transform a into b. Every
+// element of a is either kept or deleted and every element of b is
+// either kept or inserted, in order, and as few elements as possible
+// are deleted and inserted. Where there is a choice, deletions are
+// yielded before insertions.
+//
+// Diff uses Myers' O(ND) algorithm, where N is the total length of
+// the sequences and D is the number of differences between them, so
+// it is fast when the sequences are similar. Both sequences are
+// collected in their entirety before anything is yielded.
+func Diff[T comparable](a, b iter.Seq[T]) iter.Seq[Edit[T]] {
+	return DiffFunc(a, b, func(v1, v2 T) bool { return v1 == v2 })
+}
+
+// DiffFunc is like [Diff] but uses a custom function to determine if
+// elements are equal. The elements of kept edits are those from a.
+func DiffFunc[T any](a, b iter.Seq[T], eq func(T, T) bool) iter.Seq[Edit[T]] {
+	return func(yield func(Edit[T]) bool) {
+		edits := myers(slices.Collect(a), slices.Collect(b), eq)
+		for _, edit := range slices.Backward(edits) {
+			if !yield(edit) {
+				return
+			}
+		}
+	}
+}
+
+// myers returns the edits that transform a into b in reverse order.
+func myers[T any](a, b []T, eq func(T, T) bool) []Edit[T] {
+	n, m := len(a), len(b)
+	off := n + m + 1
+	v := make([]int, 2*off+1)
+
+	// trace[d] holds the portion of v, for diagonals -d-1 through d+1,
+	// from before step d so that the path can be recovered.
+	var trace [][]int
+
+search:
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, slices.Clone(v[off-d-1:off+d+2]))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && eq(a[x], b[y]) {
+				x++
+				y++
+			}
+			v[off+k] = x
+
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	edits := make([]Edit[T], 0, n+m)
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		prevK := k - 1
+		if k == -d || (k != d && v[k-1+d+1] < v[k+1+d+1]) {
+			prevK = k + 1
+		}
+		prevX := v[prevK+d+1]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, Edit[T]{Op: EditEqual, A: x, B: y, V: a[x]})
+		}
+		if d == 0 {
+			break
+		}
+
+		if x == prevX {
+			edits = append(edits, Edit[T]{Op: EditInsert, A: prevX, B: prevY, V: b[prevY]})
+		} else {
+			edits = append(edits, Edit[T]{Op: EditDelete, A: prevX, B: prevY, V: a[prevX]})
+		}
+		x, y = prevX, prevY
+	}
+
+	return edits
+}
+
+// Hunk is a group of nearby edits along with some surrounding
+// unchanged context, as yielded by [Hunks].
+type Hunk[T any] struct {
+	// AStart and BStart are the indices in the first and second
+	// sequences of the start of the hunk and ALen and BLen are the
+	// number of elements of each that it covers.
+	AStart, ALen int
+	BStart, BLen int
+
+	Edits []Edit[T]
+}
+
+// Header returns the header of h in the format used by unified
+// diffs, such as "@@ -1,4 +1,5 @@". Line numbers in the header are
+// 1-based.
+func (h Hunk[T]) Header() string {
+	return fmt.Sprintf("@@ -%v +%v @@", hunkRange(h.AStart, h.ALen), hunkRange(h.BStart, h.BLen))
+}
+
+func hunkRange(start, n int) string {
+	switch n {
+	case 0:
+		return fmt.Sprintf("%v,0", start)
+	case 1:
+		return fmt.Sprint(start + 1)
+	default:
+		return fmt.Sprintf("%v,%v", start+1, n)
+	}
+}
+
+// Hunks groups the edits yielded by edits, such as those from [Diff],
+// into hunks in the manner of a unified diff. Each hunk includes up to
+// context unchanged elements before and after its changes, and
+// changes that are close enough together that their context would
+// overlap are placed in the same hunk. Edits that are not near any
+// changes are omitted entirely.
+func Hunks[T any](edits iter.Seq[Edit[T]], context int) iter.Seq[Hunk[T]] {
+	return func(yield func(Hunk[T]) bool) {
+		all := slices.Collect(edits)
+		context := max(context, 0)
+
+		for i := 0; i < len(all); {
+			if all[i].Op == EditEqual {
+				i++
+				continue
+			}
+
+			start := max(i-context, 0)
+			end := i + 1
+			for j := end; j < len(all) && j <= end+2*context; j++ {
+				if all[j].Op != EditEqual {
+					end = j + 1
+				}
+			}
+			end = min(end+context, len(all))
+
+			h := Hunk[T]{
+				AStart: all[start].A,
+				BStart: all[start].B,
+				Edits:  all[start:end:end],
+			}
+			for _, edit := range h.Edits {
+				if edit.Op != EditInsert {
+					h.ALen++
+				}
+				if edit.Op != EditDelete {
+					h.BLen++
+				}
+			}
+			if !yield(h) {
+				return
+			}
+
+			i = end
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package xiter
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a, b := "ABCABBA", "CBABAC"
+	edits := slices.Collect(Diff(Bytes(a), Bytes(b)))
+
+	var ra, rb []byte
+	var changes int
+	for _, edit := range edits {
+		if edit.Op != EditInsert {
+			if a[edit.A] != edit.V {
+				t.Fatalf("%+v", edit)
+			}
+			ra = append(ra, edit.V)
+		}
+		if edit.Op != EditDelete {
+			if b[edit.B] != edit.V {
+				t.Fatalf("%+v", edit)
+			}
+			rb = append(rb, edit.V)
+		}
+		if edit.Op != EditEqual {
+			changes++
+		}
+	}
+	if string(ra) != a || string(rb) != b || changes != 5 {
+		t.Fatalf("%q, %q, %v", ra, rb, changes)
+	}
+
+	fold := slices.Collect(DiffFunc(Of("a", "B"), Of("A", "b", "c"), strings.EqualFold))
+	if !slices.Equal(fold, []Edit[string]{{EditEqual, 0, 0, "a"}, {EditEqual, 1, 1, "B"}, {EditInsert, 2, 2, "c"}}) {
+		t.Fatal(fold)
+	}
+}
+
+func TestHunks(t *testing.T) {
+	a := StringSplit("1 2 3 4 5 6 7 8 9 10 11 12", " ")
+	b := StringSplit("1 2 x 4 5 6 7 8 9 10 12", " ")
+
+	var buf strings.Builder
+	for h := range Hunks(Diff(a, b), 2) {
+		buf.WriteString(h.Header() + "\n")
+		for _, edit := range h.Edits {
+			buf.WriteString(edit.Op.String() + edit.V + "\n")
+		}
+	}
+
+	expected := `@@ -1,5 +1,5 @@
+ 1
+ 2
+-3
++x
+ 4
+ 5
+@@ -9,4 +9,3 @@
+ 9
+ 10
+-11
+ 12
+`
+	if buf.String() != expected {
+		t.Fatal(buf.String())
+	}
+}
@@ -0,0 +1,467 @@
+package xiter
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"iter"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalidSurrogate is wrapped by a [DecodeError] when a UTF-16
+	// surrogate is not part of a valid surrogate pair.
+	ErrInvalidSurrogate = errors.New("invalid surrogate")
+
+	// ErrTruncated is wrapped by a [DecodeError] when the input ends in
+	// the middle of an encoded value.
+	ErrTruncated = errors.New("truncated input")
+
+	// ErrUndefined is wrapped by a [DecodeError] when the input
+	// contains a byte that has no mapping in the character set being
+	// decoded.
+	ErrUndefined = errors.New("undefined character")
+
+	// ErrInvalidEncoding is wrapped by a [DecodeError] when base64,
+	// base32 or hex encoded input is malformed.
+	ErrInvalidEncoding = errors.New("invalid encoding")
+
+	// ErrOverflow is wrapped by a [DecodeError] when a varint is too
+	// large to fit into 64 bits.
+	ErrOverflow = errors.New("varint overflows 64 bits")
+)
+
+// A DecodeError is yielded by the decoding transforms in this package
+// when they encounter invalid input.
+type DecodeError struct {
+	// Offset is the offset, in bytes from the start of the input, of
+	// the invalid data.
+	Offset int64
+
+	// Err describes what was wrong with the input.
+	Err error
+}
+
+func (err *DecodeError) Error() string {
+	return fmt.Sprintf("invalid input at offset %v: %v", err.Offset, err.Err)
+}
+
+func (err *DecodeError) Unwrap() error {
+	return err.Err
+}
+
+// bytesOf returns a Seq over the individual bytes yielded by seq,
+// regardless of whether it yields them one at a time or in slices.
+func bytesOf[B byte | []byte](seq iter.Seq[B]) iter.Seq[byte] {
+	switch seq := any(seq).(type) {
+	case iter.Seq[byte]:
+		return seq
+	case iter.Seq[[]byte]:
+		return func(yield func(byte) bool) {
+			for b := range seq {
+				for _, c := range b {
+					if !yield(c) {
+						return
+					}
+				}
+			}
+		}
+	default:
+		panic("unreachable")
+	}
+}
+
+// DecodeUTF16 returns a Seq that decodes the UTF-16 encoded bytes
+// yielded by seq into runes. If the input starts with a byte order
+// mark, it is used to determine the endianness of the input and is
+// then discarded. Otherwise, the input is assumed to be big-endian.
+//
+// Invalid input, such as an unpaired surrogate or a trailing odd
+// byte, is yielded as [utf8.RuneError] along with a [*DecodeError].
+// Decoding continues afterwards unless the iteration is stopped.
+func DecodeUTF16[B byte | []byte](seq iter.Seq[B]) iter.Seq2[rune, error] {
+	return decodeUTF16(bytesOf(seq), binary.BigEndian, true)
+}
+
+// DecodeUTF16LE is like [DecodeUTF16] but always decodes the input as
+// little-endian. A leading byte order mark is decoded like any other
+// character.
+func DecodeUTF16LE[B byte | []byte](seq iter.Seq[B]) iter.Seq2[rune, error] {
+	return decodeUTF16(bytesOf(seq), binary.LittleEndian, false)
+}
+
+// DecodeUTF16BE is like [DecodeUTF16] but always decodes the input as
+// big-endian. A leading byte order mark is decoded like any other
+// character.
+func DecodeUTF16BE[B byte | []byte](seq iter.Seq[B]) iter.Seq2[rune, error] {
+	return decodeUTF16(bytesOf(seq), binary.BigEndian, false)
+}
+
+func decodeUTF16(seq iter.Seq[byte], order binary.ByteOrder, sniff bool) iter.Seq2[rune, error] {
+	return func(yield func(rune, error) bool) {
+		order, sniff := order, sniff
+
+		var (
+			high    rune
+			highOff int64
+			pending bool
+		)
+		decode := func(u rune, off int64) bool {
+			if pending {
+				pending = false
+				if 0xDC00 <= u && u < 0xE000 {
+					return yield(utf16.DecodeRune(high, u), nil)
+				}
+				if !yield(utf8.RuneError, &DecodeError{Offset: highOff, Err: ErrInvalidSurrogate}) {
+					return false
+				}
+			}
+
+			switch {
+			case 0xD800 <= u && u < 0xDC00:
+				high, highOff, pending = u, off, true
+				return true
+			case 0xDC00 <= u && u < 0xE000:
+				return yield(utf8.RuneError, &DecodeError{Offset: off, Err: ErrInvalidSurrogate})
+			default:
+				return yield(u, nil)
+			}
+		}
+
+		var buf [2]byte
+		var n int
+		var off int64
+		for c := range seq {
+			buf[n] = c
+			n++
+			if n < len(buf) {
+				continue
+			}
+			n = 0
+
+			u := rune(order.Uint16(buf[:]))
+			uoff := off
+			off += int64(len(buf))
+
+			if sniff {
+				sniff = false
+				switch u {
+				case 0xFEFF:
+					continue
+				case 0xFFFE:
+					order = binary.LittleEndian
+					continue
+				}
+			}
+
+			if !decode(u, uoff) {
+				return
+			}
+		}
+
+		if pending && !yield(utf8.RuneError, &DecodeError{Offset: highOff, Err: ErrInvalidSurrogate}) {
+			return
+		}
+		if n != 0 {
+			yield(utf8.RuneError, &DecodeError{Offset: off, Err: ErrTruncated})
+		}
+	}
+}
+
+// DecodeLatin1 returns a Seq that decodes the ISO 8859-1 encoded
+// bytes yielded by seq into runes. Every byte maps directly to the
+// rune with the same value, so decoding cannot fail.
+func DecodeLatin1[B byte | []byte](seq iter.Seq[B]) iter.Seq[rune] {
+	return Map(bytesOf(seq), func(c byte) rune { return rune(c) })
+}
+
+// windows1252 maps the bytes 0x80 through 0x9F, the only ones in
+// Windows-1252 that differ from ISO 8859-1, to their runes. Undefined
+// bytes are mapped to 0.
+var windows1252 = [32]rune{
+	'€', 0, '‚', 'ƒ', '„', '…', '†', '‡',
+	'ˆ', '‰', 'Š', '‹', 'Œ', 0, 'Ž', 0,
+	0, '‘', '’', '“', '”', '•', '–', '—',
+	'˜', '™', 'š', '›', 'œ', 0, 'ž', 'Ÿ',
+}
+
+// DecodeWindows1252 returns a Seq that decodes the Windows-1252
+// encoded bytes yielded by seq into runes. The five bytes that are
+// undefined in Windows-1252 are yielded as [utf8.RuneError] along
+// with a [*DecodeError]. Decoding continues afterwards unless the
+// iteration is stopped.
+func DecodeWindows1252[B byte | []byte](seq iter.Seq[B]) iter.Seq2[rune, error] {
+	return func(yield func(rune, error) bool) {
+		var off int64
+		for c := range bytesOf(seq) {
+			r, err := rune(c), error(nil)
+			if 0x80 <= c && c < 0xA0 {
+				r = windows1252[c-0x80]
+				if r == 0 {
+					r, err = utf8.RuneError, &DecodeError{Offset: off, Err: ErrUndefined}
+				}
+			}
+			if !yield(r, err) {
+				return
+			}
+			off++
+		}
+	}
+}
+
+// textEncoding is a binary-to-text encoding, such as base64, that
+// encodes blocks of bytes into quanta of characters.
+type textEncoding interface {
+	AppendEncode(dst, src []byte) []byte
+	AppendDecode(dst, src []byte) ([]byte, error)
+}
+
+type hexEncoding struct{}
+
+func (hexEncoding) AppendEncode(dst, src []byte) []byte {
+	return hex.AppendEncode(dst, src)
+}
+
+func (hexEncoding) AppendDecode(dst, src []byte) ([]byte, error) {
+	return hex.AppendDecode(dst, src)
+}
+
+// encode returns a Seq that encodes the data yielded by seq using enc,
+// which encodes blocks of size bytes at a time. Data that does not
+// fill a block is held until more data arrives or seq ends.
+func encode(seq iter.Seq[[]byte], enc textEncoding, size int) iter.Seq[[]byte] {
+	return func(yield func([]byte) bool) {
+		var pending, out []byte
+		for data := range seq {
+			pending = append(pending, data...)
+			n := len(pending) - len(pending)%size
+			if n == 0 {
+				continue
+			}
+
+			out = enc.AppendEncode(out[:0], pending[:n])
+			pending = append(pending[:0], pending[n:]...)
+			if !yield(out) {
+				return
+			}
+		}
+
+		if len(pending) != 0 {
+			yield(enc.AppendEncode(out[:0], pending))
+		}
+	}
+}
+
+// decodable returns the length of the longest prefix of data that
+// contains only whole quanta of size characters, not counting
+// newlines, and that ends before any padding. Padding is held back so
+// that it can be checked against whatever follows it.
+func decodable(data []byte, size int) int {
+	var n, end int
+	for i, c := range data {
+		switch c {
+		case '\r', '\n':
+			continue
+		case '=':
+			return end
+		}
+
+		n++
+		if n%size == 0 {
+			end = i + 1
+		}
+	}
+	return end
+}
+
+// decode returns a Seq that decodes the data yielded by seq using
+// enc, which decodes quanta of size characters at a time. Offsets in
+// errors returned by enc are converted to offsets from the start of
+// seq.
+func decode(seq iter.Seq[[]byte], enc textEncoding, size int) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		var pending, out []byte
+		var off int64
+		flush := func(n int) bool {
+			var err error
+			out, err = enc.AppendDecode(out[:0], pending[:n])
+			if err != nil {
+				if len(out) == 0 || yield(out, nil) {
+					yield(nil, decodeError(off, pending[:n], len(out), err))
+				}
+				return false
+			}
+
+			off += int64(n)
+			pending = append(pending[:0], pending[n:]...)
+			return len(out) == 0 || yield(out, nil)
+		}
+
+		for data := range seq {
+			pending = append(pending, data...)
+			n := decodable(pending, size)
+			if n != 0 && !flush(n) {
+				return
+			}
+		}
+
+		if len(pending) != 0 {
+			flush(len(pending))
+		}
+	}
+}
+
+func decodeError(off int64, src []byte, decoded int, err error) *DecodeError {
+	switch err := err.(type) {
+	case base64.CorruptInputError:
+		return &DecodeError{Offset: off + int64(err), Err: ErrInvalidEncoding}
+	case base32.CorruptInputError:
+		return &DecodeError{Offset: off + int64(err), Err: ErrInvalidEncoding}
+	case hex.InvalidByteError:
+		i := 2 * decoded
+		if isHex(src[i]) {
+			i++
+		}
+		return &DecodeError{Offset: off + int64(i), Err: ErrInvalidEncoding}
+	}
+
+	if err == hex.ErrLength {
+		return &DecodeError{Offset: off + int64(len(src)), Err: ErrTruncated}
+	}
+	return &DecodeError{Offset: off, Err: err}
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+// EncodeBase64 returns a Seq that encodes the data yielded by seq
+// using enc, which may be any of the encodings provided by
+// [encoding/base64], such as [base64.StdEncoding] or
+// [base64.RawURLEncoding]. The data may be split across the slices
+// yielded by seq in any way. The yielded slice is reused between
+// iterations.
+func EncodeBase64(seq iter.Seq[[]byte], enc *base64.Encoding) iter.Seq[[]byte] {
+	return encode(seq, enc, 3)
+}
+
+// DecodeBase64 returns a Seq that decodes the base64 data yielded by
+// seq using enc. Newlines in the input are ignored. If the input is
+// malformed, any data that was decoded successfully before the
+// problem is yielded first, followed by a [*DecodeError] containing
+// the offset of the problem from the start of the input, and then
+// iteration ends. The yielded slice is reused between iterations.
+func DecodeBase64(seq iter.Seq[[]byte], enc *base64.Encoding) iter.Seq2[[]byte, error] {
+	return decode(seq, enc, 4)
+}
+
+// EncodeBase32 is like [EncodeBase64] but uses a base32 encoding.
+func EncodeBase32(seq iter.Seq[[]byte], enc *base32.Encoding) iter.Seq[[]byte] {
+	return encode(seq, enc, 5)
+}
+
+// DecodeBase32 is like [DecodeBase64] but uses a base32 encoding.
+func DecodeBase32(seq iter.Seq[[]byte], enc *base32.Encoding) iter.Seq2[[]byte, error] {
+	return decode(seq, enc, 8)
+}
+
+// EncodeHex is like [EncodeBase64] but produces lowercase hexadecimal.
+func EncodeHex(seq iter.Seq[[]byte]) iter.Seq[[]byte] {
+	return encode(seq, hexEncoding{}, 1)
+}
+
+// DecodeHex is like [DecodeBase64] but decodes hexadecimal. Unlike
+// base64 and base32, newlines in the input are not allowed.
+func DecodeHex(seq iter.Seq[[]byte]) iter.Seq2[[]byte, error] {
+	return decode(seq, hexEncoding{}, 2)
+}
+
+// DeltaEncode returns a Seq that yields the first value of seq
+// followed by the difference between each subsequent value and the
+// one before it. Sequences of close values, such as sorted IDs or
+// timestamps, turn into sequences of small values that encode
+// compactly with [EncodeVarints]. Overflow wraps around, so
+// [DeltaDecode] always restores the original values.
+func DeltaEncode[T Integer](seq iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		var prev T
+		seq(func(v T) bool {
+			d := v - prev
+			prev = v
+			return yield(d)
+		})
+	}
+}
+
+// DeltaDecode reverses [DeltaEncode], yielding the running total of
+// the values of seq.
+func DeltaDecode[T Integer](seq iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		var total T
+		seq(func(d T) bool {
+			total += d
+			return yield(total)
+		})
+	}
+}
+
+// EncodeVarints returns a Seq over the bytes of the values of seq
+// encoded as zig-zag varints in the same format as
+// [binary.AppendVarint]. Values with small magnitudes, whether
+// positive or negative, take up fewer bytes.
+func EncodeVarints(seq iter.Seq[int64]) iter.Seq[byte] {
+	return func(yield func(byte) bool) {
+		var buf [binary.MaxVarintLen64]byte
+		seq(func(v int64) bool {
+			for _, c := range binary.AppendVarint(buf[:0], v) {
+				if !yield(c) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+}
+
+// DecodeVarints reverses [EncodeVarints]. If the input ends in the
+// middle of a value or contains a value that does not fit into an
+// int64, a [*DecodeError] containing the offset of the start of that
+// value is yielded and iteration ends.
+func DecodeVarints[B byte | []byte](seq iter.Seq[B]) iter.Seq2[int64, error] {
+	return func(yield func(int64, error) bool) {
+		var buf [binary.MaxVarintLen64]byte
+		var n int
+		var start, off int64
+		for c := range bytesOf(seq) {
+			buf[n] = c
+			n++
+			off++
+
+			if c&0x80 != 0 {
+				if n == len(buf) {
+					yield(0, &DecodeError{Offset: start, Err: ErrOverflow})
+					return
+				}
+				continue
+			}
+
+			v, size := binary.Varint(buf[:n])
+			if size <= 0 {
+				yield(0, &DecodeError{Offset: start, Err: ErrOverflow})
+				return
+			}
+			if !yield(v, nil) {
+				return
+			}
+			n, start = 0, off
+		}
+
+		if n != 0 {
+			yield(0, &DecodeError{Offset: start, Err: ErrTruncated})
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package xiter
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestDecodeUTF16(t *testing.T) {
+	le := []byte{0xFF, 0xFE, 'h', 0, 'i', 0, 0x3D, 0xD8, 0x00, 0xDE}
+	s := slices.Collect(V1(DecodeUTF16(slices.Values(le))))
+	if string(s) != "hi😀" {
+		t.Fatalf("%q", string(s))
+	}
+
+	be := slices.Values([][]byte{{0, 'h', 0xD8}, {0x3D, 0, 'i', 0}})
+	var runes []rune
+	var errs []error
+	for r, err := range DecodeUTF16BE(be) {
+		runes = append(runes, r)
+		errs = append(errs, err)
+	}
+	if !slices.Equal(runes, []rune{'h', utf8.RuneError, 'i', utf8.RuneError}) {
+		t.Fatalf("%q", runes)
+	}
+	var derr *DecodeError
+	if !errors.As(errs[1], &derr) || derr.Offset != 2 || !errors.Is(derr, ErrInvalidSurrogate) {
+		t.Fatal(errs[1])
+	}
+	if !errors.As(errs[3], &derr) || derr.Offset != 6 || !errors.Is(derr, ErrTruncated) {
+		t.Fatal(errs[3])
+	}
+}
+
+func TestDecodeWindows1252(t *testing.T) {
+	var s []rune
+	for r, err := range DecodeWindows1252(Bytes("\x93caf\xe9\x94 \x80\x81")) {
+		if err != nil {
+			if !errors.Is(err, ErrUndefined) {
+				t.Fatal(err)
+			}
+			continue
+		}
+		s = append(s, r)
+	}
+	if string(s) != "“café” €" {
+		t.Fatalf("%q", string(s))
+	}
+
+	s = slices.Collect(DecodeLatin1(Bytes("caf\xe9\x93")))
+	if string(s) != "café\u0093" {
+		t.Fatalf("%q", string(s))
+	}
+}
+
+func TestBase64(t *testing.T) {
+	data := Of([]byte("th"), []byte("is is a"), []byte(" test"))
+	enc := StringJoin(Map(EncodeBase64(data, base64.StdEncoding), func(b []byte) string { return string(b) }), "")
+	if enc != base64.StdEncoding.EncodeToString([]byte("this is a test")) {
+		t.Fatal(enc)
+	}
+
+	chunks := Map(Chunks(Bytes(enc[:5]+"\n"+enc[5:]), 3), bytes.Clone)
+	var dec []byte
+	for b, err := range DecodeBase64(chunks, base64.StdEncoding) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec = append(dec, b...)
+	}
+	if string(dec) != "this is a test" {
+		t.Fatalf("%q", dec)
+	}
+
+	var derr *DecodeError
+	dec = nil
+	for b, err := range DecodeBase64(Of([]byte("QQ=="), []byte("QQ==")), base64.StdEncoding) {
+		if err != nil {
+			if !errors.As(err, &derr) || derr.Offset != 4 {
+				t.Fatal(err)
+			}
+			break
+		}
+		dec = append(dec, b...)
+	}
+	if string(dec) != "A" || derr == nil {
+		t.Fatalf("%q", dec)
+	}
+}
+
+func TestBase32(t *testing.T) {
+	data := Of([]byte("test"), []byte("ing"))
+	enc := slices.Concat(slices.Collect(Map(EncodeBase32(data, base32.StdEncoding), bytes.Clone))...)
+	if string(enc) != base32.StdEncoding.EncodeToString([]byte("testing")) {
+		t.Fatalf("%q", enc)
+	}
+
+	dec := slices.Concat(slices.Collect(Map(V1(DecodeBase32(Of(enc[:3], enc[3:]), base32.StdEncoding)), bytes.Clone))...)
+	if string(dec) != "testing" {
+		t.Fatalf("%q", dec)
+	}
+}
+
+func TestHex(t *testing.T) {
+	enc := slices.Concat(slices.Collect(Map(EncodeHex(Of([]byte("ab"), []byte("c"))), bytes.Clone))...)
+	if string(enc) != "616263" {
+		t.Fatalf("%q", enc)
+	}
+
+	var dec []byte
+	var derr *DecodeError
+	for b, err := range DecodeHex(Of([]byte("616"), []byte("2x3"))) {
+		if err != nil {
+			if !errors.As(err, &derr) || derr.Offset != 4 {
+				t.Fatal(err)
+			}
+			break
+		}
+		dec = append(dec, b...)
+	}
+	if string(dec) != "ab" || derr == nil {
+		t.Fatalf("%q", dec)
+	}
+
+	dec, derr = nil, nil
+	for b, err := range DecodeHex(Of([]byte("6162x3"))) {
+		if err != nil {
+			if !errors.As(err, &derr) || derr.Offset != 4 || !errors.Is(err, ErrInvalidEncoding) {
+				t.Fatal(err)
+			}
+			break
+		}
+		dec = append(dec, b...)
+	}
+	if string(dec) != "ab" || derr == nil {
+		t.Fatalf("%q", dec)
+	}
+}
+
+func TestDelta(t *testing.T) {
+	s := slices.Collect(DeltaEncode(Of[uint8](3, 5, 10, 2)))
+	if !slices.Equal(s, []uint8{3, 2, 5, 248}) {
+		t.Fatal(s)
+	}
+
+	s = slices.Collect(DeltaDecode(slices.Values(s)))
+	if !slices.Equal(s, []uint8{3, 5, 10, 2}) {
+		t.Fatal(s)
+	}
+}
+
+func TestVarints(t *testing.T) {
+	vals := []int64{0, -1, 1, 300, -70000, 1 << 62}
+	enc := slices.Collect(EncodeVarints(slices.Values(vals)))
+	if enc[0] != 0 || enc[1] != 1 || enc[2] != 2 {
+		t.Fatal(enc)
+	}
+
+	var dec []int64
+	for v, err := range DecodeVarints(slices.Values(enc)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec = append(dec, v)
+	}
+	if !slices.Equal(dec, vals) {
+		t.Fatal(dec)
+	}
+
+	var derr *DecodeError
+	errs := slices.Collect(V2(DecodeVarints(Of([]byte{2}, []byte{0x80}))))
+	if !errors.As(errs[1], &derr) || derr.Offset != 1 || !errors.Is(derr, ErrTruncated) {
+		t.Fatal(errs)
+	}
+}
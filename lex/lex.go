@@ -0,0 +1,246 @@
+// Package lex provides a framework for writing lexers out of state
+// functions in the style described by Rob Pike in his talk "Lexical
+// Scanning in Go". A lexer reads runes from an [iter.Seq] and yields
+// tokens via an [iter.Seq2], so it can be fed from sources such as
+// [deedles.dev/xiter.Runes] and consumed lazily.
+package lex
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+)
+
+// EOF is returned by [Lexer.Next] and [Lexer.Peek] when the input has
+// been exhausted.
+const EOF rune = -1
+
+// Pos is a position in the input of a lexer.
+type Pos struct {
+	// Offset is the number of runes preceding the position.
+	Offset int
+
+	// Line and Column are the 1-based line and column, in runes, of
+	// the position.
+	Line, Column int
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%v:%v", p.Line, p.Column)
+}
+
+// advance returns the position after r if r is at p.
+func (p Pos) advance(r rune) Pos {
+	p.Offset++
+	p.Column++
+	if r == '\n' {
+		p.Line++
+		p.Column = 1
+	}
+	return p
+}
+
+// Kind identifies the kind of a token. The meanings of specific
+// values are determined by the state functions that emit them.
+type Kind int
+
+// Token is a token yielded by a lexer.
+type Token struct {
+	Kind Kind
+	Val  string
+
+	// Start is the position of the first rune of the token and End is
+	// the position immediately after its last rune.
+	Start, End Pos
+}
+
+// StateFn is a state of a lexer. It is called with the lexer and
+// returns the next state, or nil to stop lexing.
+type StateFn func(*Lexer) StateFn
+
+// Error is yielded by a lexer when a state function calls
+// [Lexer.Errorf].
+type Error struct {
+	// Pos is the position of the start of the token that caused the
+	// error.
+	Pos Pos
+	Msg string
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("%v: %v", err.Pos, err.Msg)
+}
+
+// Lexer holds the state of a lexer while it is running. State
+// functions use its methods to read input and emit tokens.
+type Lexer struct {
+	next  func() (rune, bool)
+	yield func(Token, error) bool
+	done  bool
+
+	// buf holds the runes of the current token and prev holds the
+	// position before each of them so that they can be backed up
+	// over.
+	buf  []rune
+	prev []Pos
+
+	// unread holds runes that have been backed up over, with the
+	// next one to be read at the end.
+	unread []rune
+
+	// eof is true if the last call to Next returned EOF.
+	eof bool
+
+	start, pos Pos
+}
+
+// Lex returns a Seq2 that runs a lexer over input, starting with the
+// state start and continuing until a state returns nil, yielding each
+// token emitted along the way. If a state calls [Lexer.Errorf], the
+// error is yielded along with the partial token at that point and
+// lexing ends. Lexing also ends as soon as the consumer stops
+// iterating.
+func Lex(input iter.Seq[rune], start StateFn) iter.Seq2[Token, error] {
+	return func(yield func(Token, error) bool) {
+		next, stop := iter.Pull(input)
+		defer stop()
+
+		l := Lexer{
+			next:  next,
+			yield: yield,
+			start: Pos{Line: 1, Column: 1},
+			pos:   Pos{Line: 1, Column: 1},
+		}
+		for state := start; state != nil && !l.done; {
+			state = state(&l)
+		}
+	}
+}
+
+// Next consumes and returns the next rune of the input, or EOF if
+// there is none.
+func (l *Lexer) Next() rune {
+	var r rune
+	if len(l.unread) > 0 {
+		r = l.unread[len(l.unread)-1]
+		l.unread = l.unread[:len(l.unread)-1]
+	} else {
+		var ok bool
+		r, ok = l.next()
+		if !ok {
+			l.eof = true
+			return EOF
+		}
+	}
+
+	l.eof = false
+	l.buf = append(l.buf, r)
+	l.prev = append(l.prev, l.pos)
+	l.pos = l.pos.advance(r)
+	return r
+}
+
+// Backup steps back over the last rune returned by Next so that it
+// will be returned again. It may be called repeatedly to step back
+// over several runes, but not past the start of the current token.
+func (l *Lexer) Backup() {
+	if l.eof {
+		l.eof = false
+		return
+	}
+	if len(l.buf) == 0 {
+		panic("lex: backup past start of token")
+	}
+
+	r := l.buf[len(l.buf)-1]
+	l.buf = l.buf[:len(l.buf)-1]
+	l.unread = append(l.unread, r)
+
+	l.pos = l.prev[len(l.prev)-1]
+	l.prev = l.prev[:len(l.prev)-1]
+}
+
+// Peek returns the next rune of the input without consuming it.
+func (l *Lexer) Peek() rune {
+	r := l.Next()
+	l.Backup()
+	return r
+}
+
+// Accept consumes the next rune if it is in valid.
+func (l *Lexer) Accept(valid string) bool {
+	return l.AcceptFunc(func(r rune) bool { return strings.ContainsRune(valid, r) })
+}
+
+// AcceptFunc consumes the next rune if f returns true for it.
+func (l *Lexer) AcceptFunc(f func(rune) bool) bool {
+	r := l.Next()
+	if r != EOF && f(r) {
+		return true
+	}
+	l.Backup()
+	return false
+}
+
+// AcceptRun consumes runes for as long as they are in valid,
+// returning the number consumed.
+func (l *Lexer) AcceptRun(valid string) int {
+	return l.AcceptRunFunc(func(r rune) bool { return strings.ContainsRune(valid, r) })
+}
+
+// AcceptRunFunc consumes runes for as long as f returns true for
+// them, returning the number consumed.
+func (l *Lexer) AcceptRunFunc(f func(rune) bool) int {
+	var n int
+	for l.AcceptFunc(f) {
+		n++
+	}
+	return n
+}
+
+// Current returns the text of the current token so far.
+func (l *Lexer) Current() string {
+	return string(l.buf)
+}
+
+// Start returns the position of the start of the current token.
+func (l *Lexer) Start() Pos {
+	return l.start
+}
+
+// Pos returns the current position in the input.
+func (l *Lexer) Pos() Pos {
+	return l.pos
+}
+
+// Emit yields the current token with the given kind and starts a new
+// one.
+func (l *Lexer) Emit(kind Kind) {
+	if !l.done {
+		l.done = !l.yield(Token{Kind: kind, Val: l.Current(), Start: l.start, End: l.pos}, nil)
+	}
+	l.Ignore()
+}
+
+// Ignore discards the current token and starts a new one.
+func (l *Lexer) Ignore() {
+	clear(l.buf)
+	l.buf = l.buf[:0]
+	l.prev = l.prev[:0]
+	l.start = l.pos
+}
+
+// Errorf yields an [*Error] at the start of the current token with a
+// message formatted from its arguments along with the current token
+// and then returns a nil state, ending lexing. It is meant to be used in
+// a state as
+//
+//	return l.Errorf("unexpected %q", r)
+func (l *Lexer) Errorf(format string, args ...any) StateFn {
+	if !l.done {
+		tok := Token{Val: l.Current(), Start: l.start, End: l.pos}
+		l.yield(tok, &Error{Pos: l.start, Msg: fmt.Sprintf(format, args...)})
+		l.done = true
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package lex
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"unicode"
+
+	"deedles.dev/xiter"
+)
+
+const (
+	kindNumber Kind = iota + 1
+	kindIdent
+	kindOp
+)
+
+func lexAny(l *Lexer) StateFn {
+	switch r := l.Next(); {
+	case r == EOF:
+		return nil
+	case unicode.IsSpace(r):
+		l.AcceptRunFunc(unicode.IsSpace)
+		l.Ignore()
+		return lexAny
+	case unicode.IsDigit(r):
+		l.Backup()
+		return lexNumber
+	case unicode.IsLetter(r):
+		l.AcceptRunFunc(func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) })
+		l.Emit(kindIdent)
+		return lexAny
+	case r == '+' || r == '*':
+		l.Emit(kindOp)
+		return lexAny
+	default:
+		return l.Errorf("unexpected %q", r)
+	}
+}
+
+func lexNumber(l *Lexer) StateFn {
+	l.AcceptRun("0123456789")
+	if l.Accept(".") {
+		if l.AcceptRun("0123456789") == 0 {
+			l.Backup()
+		}
+	}
+	l.Emit(kindNumber)
+	return lexAny
+}
+
+func TestLex(t *testing.T) {
+	var toks []Token
+	for tok, err := range Lex(xiter.Runes("x1 + 3.25*\n 7."), lexAny) {
+		if err != nil {
+			var lerr *Error
+			if !errors.As(err, &lerr) || lerr.Pos != (Pos{Offset: 13, Line: 2, Column: 3}) {
+				t.Fatal(err)
+			}
+			break
+		}
+		toks = append(toks, tok)
+	}
+
+	expected := []Token{
+		{Kind: kindIdent, Val: "x1", Start: Pos{0, 1, 1}, End: Pos{2, 1, 3}},
+		{Kind: kindOp, Val: "+", Start: Pos{3, 1, 4}, End: Pos{4, 1, 5}},
+		{Kind: kindNumber, Val: "3.25", Start: Pos{5, 1, 6}, End: Pos{9, 1, 10}},
+		{Kind: kindOp, Val: "*", Start: Pos{9, 1, 10}, End: Pos{10, 1, 11}},
+		{Kind: kindNumber, Val: "7", Start: Pos{12, 2, 2}, End: Pos{13, 2, 3}},
+	}
+	if !slices.Equal(toks, expected) {
+		t.Fatalf("%+v", toks)
+	}
+}
+
+func TestLexStop(t *testing.T) {
+	var n int
+	for range Lex(xiter.Runes("a b c d"), lexAny) {
+		n++
+		if n == 2 {
+			break
+		}
+	}
+	if n != 2 {
+		t.Fatal(n)
+	}
+}
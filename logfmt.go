@@ -0,0 +1,157 @@
+package xiter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseLogfmt returns an iterator over the key/value pairs of a
+// single line of logfmt, such as
+//
+//	level=info msg="request finished" path=/ duration=3ms cached
+//
+// Values may be bare, in which case they extend to the next
+// whitespace, or quoted using Go's double-quoted string syntax,
+// including its escapes. A key with no value, such as cached above,
+// is yielded with an empty value. Bare values are yielded as
+// substrings of line, so no copying is done. Malformed input is
+// handled leniently: an unterminated quoted value extends to the end
+// of line and stray characters that cannot begin a key are skipped.
+func ParseLogfmt(line string) iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for i := 0; i < len(line); {
+			if isLogfmtSpace(line[i]) {
+				i++
+				continue
+			}
+
+			start := i
+			for i < len(line) && isLogfmtKey(line[i]) {
+				i++
+			}
+			key := line[start:i]
+			if key == "" {
+				i++
+				continue
+			}
+
+			var val string
+			if i < len(line) && line[i] == '=' {
+				i++
+				val, i = parseLogfmtValue(line, i)
+			}
+			if !yield(key, val) {
+				return
+			}
+		}
+	}
+}
+
+// parseLogfmtValue parses the value starting at i in line, returning
+// it and the index of the end of it.
+func parseLogfmtValue(line string, i int) (string, int) {
+	if i == len(line) || line[i] != '"' {
+		start := i
+		for i < len(line) && !isLogfmtSpace(line[i]) {
+			i++
+		}
+		return line[start:i], i
+	}
+
+	start := i
+	for i++; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			quoted := line[start : i+1]
+			if val, err := strconv.Unquote(quoted); err == nil {
+				return val, i + 1
+			}
+			return quoted[1 : len(quoted)-1], i + 1
+		}
+	}
+	return line[start+1:], len(line)
+}
+
+func isLogfmtSpace(c byte) bool {
+	return c <= ' '
+}
+
+func isLogfmtKey(c byte) bool {
+	return c > ' ' && c != '=' && c != '"'
+}
+
+// ReadLogfmt returns an iterator over the records of the logfmt data
+// read from r, one per line, with each parsed by [ParseLogfmt] into a
+// map. Lines may be of any length. Blank lines are skipped. If a key
+// appears more than once in a record, the last value wins. If reading
+// from r fails, the error is yielded and iteration ends. Like the
+// other reader-backed sources in this package, reaching the end of r
+// yields [io.EOF], which can be handled with [UntilEOF].
+func ReadLogfmt(r io.Reader) iter.Seq2[map[string]string, error] {
+	return func(yield func(map[string]string, error) bool) {
+		br := bufio.NewReader(r)
+		for {
+			line, err := br.ReadString('\n')
+			if strings.TrimSpace(line) != "" {
+				line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+				if !yield(maps.Collect(ParseLogfmt(line)), nil) {
+					return
+				}
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+		}
+	}
+}
+
+// WriteLogfmt writes the key/value pairs yielded by record to w as a
+// single line of logfmt, in the order that they are yielded. Values
+// that cannot be written bare are quoted. To write a map with a
+// stable key order, use [SortedMap]. If a key is empty or contains
+// characters that are not allowed in logfmt keys, nothing is written
+// and an error is returned.
+func WriteLogfmt(w io.Writer, record iter.Seq2[string, string]) error {
+	var buf []byte
+	for key, val := range record {
+		if key == "" || strings.IndexFunc(key, func(r rune) bool { return r < utf8.RuneSelf && !isLogfmtKey(byte(r)) }) >= 0 {
+			return fmt.Errorf("invalid logfmt key: %q", key)
+		}
+
+		if len(buf) != 0 {
+			buf = append(buf, ' ')
+		}
+		buf = append(buf, key...)
+		buf = append(buf, '=')
+		if needsLogfmtQuote(val) {
+			buf = strconv.AppendQuote(buf, val)
+			continue
+		}
+		buf = append(buf, val...)
+	}
+
+	buf = append(buf, '\n')
+	_, err := w.Write(buf)
+	return err
+}
+
+func needsLogfmtQuote(val string) bool {
+	if !utf8.ValidString(val) {
+		return true
+	}
+	for _, r := range val {
+		if r <= ' ' || r == '=' || r == '"' || !strconv.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
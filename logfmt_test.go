@@ -0,0 +1,75 @@
+package xiter
+
+import (
+	"bytes"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseLogfmt(t *testing.T) {
+	s := slices.Collect(ToPair(ParseLogfmt(`level=info msg="request \"done\"\n" path=/ cached  empty= ="junk" tail="unterminated`)))
+	if !slices.Equal(s, []Pair[string, string]{
+		{"level", "info"},
+		{"msg", "request \"done\"\n"},
+		{"path", "/"},
+		{"cached", ""},
+		{"empty", ""},
+		{"junk", ""},
+		{"tail", "unterminated"},
+	}) {
+		t.Fatalf("%q", s)
+	}
+}
+
+func TestReadLogfmt(t *testing.T) {
+	var s []map[string]string
+	for m, err := range UntilEOF(ReadLogfmt(strings.NewReader("a=1 b=2\n\n  \na=3 a=4\n"))) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		s = append(s, m)
+	}
+	if !slices.EqualFunc(s, []map[string]string{{"a": "1", "b": "2"}, {"a": "4"}}, maps.Equal) {
+		t.Fatal(s)
+	}
+
+	long := strings.Repeat("x", 100000)
+	s = slices.Collect(V1(UntilEOF(ReadLogfmt(strings.NewReader("msg=" + long + "\r\nlast=1")))))
+	if !slices.EqualFunc(s, []map[string]string{{"msg": long}, {"last": "1"}}, maps.Equal) {
+		t.Fatal(len(s))
+	}
+
+	var last error
+	for _, err := range ReadLogfmt(strings.NewReader("")) {
+		last = err
+	}
+	if last != io.EOF {
+		t.Fatal(last)
+	}
+}
+
+func TestWriteLogfmt(t *testing.T) {
+	var buf bytes.Buffer
+	record := map[string]string{"msg": "hello world", "level": "info", "empty": "", "eq": "a=b"}
+	err := WriteLogfmt(&buf, SortedMap(record))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "empty= eq=\"a=b\" level=info msg=\"hello world\"\n" {
+		t.Fatalf("%q", buf.String())
+	}
+
+	got := maps.Collect(ParseLogfmt(buf.String()))
+	if !maps.Equal(got, record) {
+		t.Fatal(got)
+	}
+
+	buf.Reset()
+	err = WriteLogfmt(&buf, SortedMap(map[string]string{"bad key": "v"}))
+	if err == nil || buf.Len() != 0 {
+		t.Fatal(err, buf.String())
+	}
+}
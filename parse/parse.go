@@ -0,0 +1,380 @@
+// Package parse provides parser combinators that operate on
+// sequences of arbitrary tokens. The same combinators work equally
+// well on runes, such as those yielded by [deedles.dev/xiter.Runes],
+// and on the tokens produced by a lexer, such as one built with
+// [deedles.dev/xiter/lex].
+//
+// Every parser in this package backtracks on failure, leaving the
+// input where it was before the parser was run, so alternatives can
+// be tried freely. When parsing fails, the resulting error reports
+// the furthest position in the input that any parser reached along
+// with everything that was expected there.
+package parse
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+
+	"deedles.dev/xiter"
+)
+
+// Cursor is a buffered, markable cursor over a sequence of tokens.
+// Parsers use it to read tokens and to return to earlier positions
+// when they fail. Tokens are only buffered for as long as a mark that
+// might return to them is outstanding.
+type Cursor[T any] struct {
+	next func() (T, bool)
+	stop func()
+	done bool
+
+	// buf holds buffered tokens, the first of which is at position
+	// off.
+	buf []T
+	off int
+	pos int
+
+	marks []int
+
+	fail     int
+	expected []string
+	found    string
+}
+
+// NewCursor returns a Cursor that reads from seq. The Cursor must be
+// stopped when it is no longer needed.
+func NewCursor[T any](seq iter.Seq[T]) *Cursor[T] {
+	next, stop := iter.Pull(seq)
+	return &Cursor[T]{next: next, stop: stop, fail: -1}
+}
+
+// Stop releases the resources associated with c.
+func (c *Cursor[T]) Stop() {
+	c.stop()
+}
+
+// Pos returns the current position of c, counted in tokens from the
+// start of the input.
+func (c *Cursor[T]) Pos() int {
+	return c.pos
+}
+
+// Next consumes and returns the next token. If there are no more
+// tokens, it returns false.
+func (c *Cursor[T]) Next() (T, bool) {
+	if len(c.marks) == 0 && c.pos > c.off {
+		clear(c.buf[:c.pos-c.off])
+		c.buf = c.buf[c.pos-c.off:]
+		c.off = c.pos
+	}
+
+	if i := c.pos - c.off; i < len(c.buf) {
+		c.pos++
+		return c.buf[i], true
+	}
+	if c.done {
+		var zero T
+		return zero, false
+	}
+
+	v, ok := c.next()
+	if !ok {
+		c.done = true
+		return v, false
+	}
+	c.buf = append(c.buf, v)
+	c.pos++
+	return v, true
+}
+
+// Peek returns the next token without consuming it.
+func (c *Cursor[T]) Peek() (T, bool) {
+	v, ok := c.Next()
+	if ok {
+		c.pos--
+	}
+	return v, ok
+}
+
+// Mark records the current position so that it can be returned to
+// with Reset. Every call to Mark must be followed by a call to either
+// Reset or Release.
+func (c *Cursor[T]) Mark() int {
+	c.marks = append(c.marks, c.pos)
+	return c.pos
+}
+
+// Reset returns to the position of the most recent mark and removes
+// it.
+func (c *Cursor[T]) Reset() {
+	c.pos = c.marks[len(c.marks)-1]
+	c.Release()
+}
+
+// Release removes the most recent mark without returning to it.
+func (c *Cursor[T]) Release() {
+	c.marks = c.marks[:len(c.marks)-1]
+}
+
+// Expected records that something described by what was expected at
+// the current position but was not found. If no parser fails further
+// into the input, it will be included in the error returned by
+// [Parse].
+func (c *Cursor[T]) Expected(what string) {
+	if c.pos < c.fail {
+		return
+	}
+	if c.pos > c.fail {
+		c.fail = c.pos
+		c.expected = c.expected[:0]
+
+		c.found = "end of input"
+		if v, ok := c.Peek(); ok {
+			c.found = describe(v)
+		}
+	}
+	c.expected = append(c.expected, what)
+}
+
+// err returns an error describing the furthest failure recorded by
+// c. If no failure was recorded, it describes the current position
+// instead.
+func (c *Cursor[T]) err() *Error {
+	if c.fail < 0 {
+		found := "end of input"
+		if v, ok := c.Peek(); ok {
+			found = describe(v)
+		}
+		return &Error{Pos: c.pos, Found: found}
+	}
+
+	expected := slices.Compact(slices.Sorted(slices.Values(c.expected)))
+	return &Error{Pos: c.fail, Expected: expected, Found: c.found}
+}
+
+// Error is returned by [Parse] when parsing fails.
+type Error struct {
+	// Pos is the furthest position in the input, counted in tokens,
+	// at which a parser failed.
+	Pos int
+
+	// Expected describes everything that would have been accepted at
+	// Pos, sorted. It is empty if the parser that failed did not
+	// record what it expected.
+	Expected []string
+
+	// Found describes what was found at Pos instead.
+	Found string
+}
+
+func (err *Error) Error() string {
+	if len(err.Expected) == 0 {
+		return fmt.Sprintf("at position %v: unexpected %v", err.Pos, err.Found)
+	}
+	return fmt.Sprintf("at position %v: expected %v but found %v", err.Pos, strings.Join(err.Expected, " or "), err.Found)
+}
+
+func describe(v any) string {
+	switch v := v.(type) {
+	case rune:
+		return strconv.QuoteRune(v)
+	case string:
+		return strconv.Quote(v)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Parser parses tokens of type T from a Cursor to produce a value of
+// type R. If it fails, it returns false and leaves the Cursor at the
+// position that it started at.
+type Parser[T, R any] func(*Cursor[T]) (R, bool)
+
+// Parse runs p on input. If p fails, the returned error is an
+// [*Error] describing the furthest failure. Parse does not require p
+// to consume all of input. To do so, combine p with [End].
+func Parse[T, R any](p Parser[T, R], input iter.Seq[T]) (R, error) {
+	c := NewCursor(input)
+	defer c.Stop()
+
+	r, ok := p(c)
+	if !ok {
+		return r, c.err()
+	}
+	return r, nil
+}
+
+// Satisfy returns a Parser that consumes a single token if f returns
+// true for it. If it does not, name is recorded as having been
+// expected.
+func Satisfy[T any](name string, f func(T) bool) Parser[T, T] {
+	return func(c *Cursor[T]) (T, bool) {
+		v, ok := c.Peek()
+		if !ok || !f(v) {
+			c.Expected(name)
+			var zero T
+			return zero, false
+		}
+		c.Next()
+		return v, true
+	}
+}
+
+// Equal returns a Parser that consumes a single token if it is equal
+// to v.
+func Equal[T comparable](v T) Parser[T, T] {
+	return Satisfy(describe(v), func(t T) bool { return t == v })
+}
+
+// Any returns a Parser that consumes any single token. It fails only
+// at the end of the input.
+func Any[T any]() Parser[T, T] {
+	return Satisfy("any token", func(T) bool { return true })
+}
+
+// End returns a Parser that succeeds only at the end of the input.
+func End[T any]() Parser[T, struct{}] {
+	return func(c *Cursor[T]) (struct{}, bool) {
+		if _, ok := c.Peek(); ok {
+			c.Expected("end of input")
+			return struct{}{}, false
+		}
+		return struct{}{}, true
+	}
+}
+
+// Seq returns a Parser that runs each of ps in turn, collecting their
+// results. It fails if any of them do.
+func Seq[T, R any](ps ...Parser[T, R]) Parser[T, []R] {
+	return func(c *Cursor[T]) ([]R, bool) {
+		c.Mark()
+		r := make([]R, 0, len(ps))
+		for _, p := range ps {
+			v, ok := p(c)
+			if !ok {
+				c.Reset()
+				return nil, false
+			}
+			r = append(r, v)
+		}
+		c.Release()
+		return r, true
+	}
+}
+
+// Seq2 is like [Seq] but runs exactly two parsers which may produce
+// values of different types.
+func Seq2[T, R1, R2 any](p1 Parser[T, R1], p2 Parser[T, R2]) Parser[T, xiter.Pair[R1, R2]] {
+	return func(c *Cursor[T]) (xiter.Pair[R1, R2], bool) {
+		c.Mark()
+		v1, ok := p1(c)
+		if !ok {
+			c.Reset()
+			return xiter.Pair[R1, R2]{}, false
+		}
+		v2, ok := p2(c)
+		if !ok {
+			c.Reset()
+			return xiter.Pair[R1, R2]{}, false
+		}
+		c.Release()
+		return xiter.P(v1, v2), true
+	}
+}
+
+// Alt returns a Parser that tries each of ps in turn, returning the
+// result of the first one that succeeds.
+func Alt[T, R any](ps ...Parser[T, R]) Parser[T, R] {
+	return func(c *Cursor[T]) (R, bool) {
+		for _, p := range ps {
+			if v, ok := p(c); ok {
+				return v, true
+			}
+		}
+		var zero R
+		return zero, false
+	}
+}
+
+// Many returns a Parser that runs p for as long as it succeeds,
+// collecting the results. It always succeeds, even if p never does.
+func Many[T, R any](p Parser[T, R]) Parser[T, []R] {
+	return func(c *Cursor[T]) ([]R, bool) {
+		var r []R
+		for {
+			start := c.Pos()
+			v, ok := p(c)
+			if !ok {
+				return r, true
+			}
+			r = append(r, v)
+
+			// Stop rather than looping forever on parsers that succeed
+			// without consuming anything.
+			if c.Pos() == start {
+				return r, true
+			}
+		}
+	}
+}
+
+// Many1 is like [Many] but fails if p does not succeed at least once.
+func Many1[T, R any](p Parser[T, R]) Parser[T, []R] {
+	many := Many(p)
+	return func(c *Cursor[T]) ([]R, bool) {
+		r, _ := many(c)
+		return r, len(r) > 0
+	}
+}
+
+// Optional returns a Parser that runs p, returning def instead of
+// failing if p fails.
+func Optional[T, R any](p Parser[T, R], def R) Parser[T, R] {
+	return func(c *Cursor[T]) (R, bool) {
+		if v, ok := p(c); ok {
+			return v, true
+		}
+		return def, true
+	}
+}
+
+// SepBy returns a Parser that parses zero or more instances of p
+// separated by instances of sep, collecting the results of p. A
+// trailing separator is not consumed.
+func SepBy[T, R, S any](p Parser[T, R], sep Parser[T, S]) Parser[T, []R] {
+	rest := Many(Map(Seq2(sep, p), func(v xiter.Pair[S, R]) R { return v.V2 }))
+	return func(c *Cursor[T]) ([]R, bool) {
+		first, ok := p(c)
+		if !ok {
+			return nil, true
+		}
+		r, _ := rest(c)
+		return append([]R{first}, r...), true
+	}
+}
+
+// Map returns a Parser that transforms the result of p using f.
+func Map[T, R1, R2 any](p Parser[T, R1], f func(R1) R2) Parser[T, R2] {
+	return func(c *Cursor[T]) (R2, bool) {
+		v, ok := p(c)
+		if !ok {
+			var zero R2
+			return zero, false
+		}
+		return f(v), true
+	}
+}
+
+// Lookahead returns a Parser that runs p but does not consume any
+// input, regardless of whether p succeeds or not.
+func Lookahead[T, R any](p Parser[T, R]) Parser[T, R] {
+	return func(c *Cursor[T]) (R, bool) {
+		c.Mark()
+		defer c.Reset()
+		return p(c)
+	}
+}
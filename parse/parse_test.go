@@ -0,0 +1,110 @@
+package parse
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"unicode"
+
+	"deedles.dev/xiter"
+	"deedles.dev/xiter/lex"
+)
+
+func number() Parser[rune, int] {
+	digits := Many1(Satisfy("digit", unicode.IsDigit))
+	return Map(digits, func(ds []rune) int {
+		n, _ := strconv.Atoi(string(ds))
+		return n
+	})
+}
+
+func list() Parser[rune, []int] {
+	items := SepBy(number(), Equal(','))
+	return Map(Seq2(Seq2(Equal('['), items), Equal(']')), func(v xiter.Pair[xiter.Pair[rune, []int], rune]) []int {
+		return v.V1.V2
+	})
+}
+
+func TestParse(t *testing.T) {
+	r, err := Parse(list(), xiter.Runes("[1,22,333]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(r, []int{1, 22, 333}) {
+		t.Fatal(r)
+	}
+
+	_, err = Parse(list(), xiter.Runes("[1,22,x]"))
+	var perr *Error
+	if !errors.As(err, &perr) {
+		t.Fatal(err)
+	}
+	if perr.Pos != 6 || !slices.Equal(perr.Expected, []string{"digit"}) || perr.Found != "'x'" {
+		t.Fatalf("%+v", perr)
+	}
+
+	_, err = Parse(list(), xiter.Runes("[1,22"))
+	if !errors.As(err, &perr) || perr.Pos != 5 || !slices.Equal(perr.Expected, []string{"','", "']'", "digit"}) || perr.Found != "end of input" {
+		t.Fatal(err)
+	}
+
+	_, err = Parse(Seq2(list(), End[rune]()), xiter.Runes("[1]2"))
+	if !errors.As(err, &perr) || perr.Pos != 3 || !slices.Equal(perr.Expected, []string{"end of input"}) {
+		t.Fatal(err)
+	}
+}
+
+func TestAlt(t *testing.T) {
+	keyword := func(s string) Parser[rune, string] {
+		return Map(Seq(slices.Collect(xiter.Map(xiter.Runes(s), Equal[rune]))...), func(r []rune) string { return string(r) })
+	}
+	p := Many(Alt(keyword("foo"), keyword("for"), keyword("f")))
+
+	r, err := Parse(p, xiter.Runes("forffoofo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(r, []string{"for", "f", "foo", "f"}) {
+		t.Fatal(r)
+	}
+
+	r, err = Parse(Lookahead(p), xiter.Runes("f"))
+	if err != nil || !slices.Equal(r, []string{"f"}) {
+		t.Fatal(r, err)
+	}
+
+	n, err := Parse(Optional(number(), -1), xiter.Runes("x"))
+	if err != nil || n != -1 {
+		t.Fatal(n, err)
+	}
+	_, err = Parse(Alt[rune, int](), xiter.Runes("x"))
+	var perr *Error
+	if !errors.As(err, &perr) || perr.Pos != 0 || err.Error() != "at position 0: unexpected 'x'" {
+		t.Fatal(err)
+	}
+}
+
+func TestParseTokens(t *testing.T) {
+	const (
+		ident lex.Kind = iota
+		assign
+	)
+	kind := func(name string, k lex.Kind) Parser[lex.Token, lex.Token] {
+		return Satisfy(name, func(tok lex.Token) bool { return tok.Kind == k })
+	}
+
+	toks := xiter.Of(
+		lex.Token{Kind: ident, Val: "x"},
+		lex.Token{Kind: assign, Val: "="},
+		lex.Token{Kind: ident, Val: "y"},
+	)
+	p := Seq(kind("identifier", ident), kind("'='", assign), kind("identifier", ident))
+	r, err := Parse(p, toks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r[0].Val != "x" || r[2].Val != "y" {
+		t.Fatal(r)
+	}
+}
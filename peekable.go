@@ -0,0 +1,103 @@
+package xiter
+
+import (
+	"iter"
+	"slices"
+)
+
+// Peekable is a pull iterator over a Seq that supports looking ahead
+// at upcoming values without consuming them and pushing values back
+// so that they will be returned again. It is useful for algorithms
+// that need to make decisions based on the next value, such as
+// lookahead parsing, without having to keep track of it manually.
+type Peekable[T any] struct {
+	next func() (T, bool)
+	stop func()
+
+	// buf holds values that have been peeked at or unread, the first
+	// of which is the next to be returned.
+	buf []T
+}
+
+// NewPeekable returns a Peekable that pulls values from seq. The
+// Peekable must be stopped when it is no longer needed.
+func NewPeekable[T any](seq iter.Seq[T]) *Peekable[T] {
+	next, stop := iter.Pull(seq)
+	return &Peekable[T]{next: next, stop: stop}
+}
+
+// Stop releases the resources associated with p and discards any
+// values that have been peeked at or unread. After it is called, only
+// values that are unread after Stop is called are returned.
+func (p *Peekable[T]) Stop() {
+	p.stop()
+	clear(p.buf)
+	p.buf = p.buf[:0]
+}
+
+// Next consumes and returns the next value. If there are no more
+// values, it returns false.
+func (p *Peekable[T]) Next() (T, bool) {
+	if len(p.buf) == 0 {
+		return p.next()
+	}
+
+	v := p.buf[0]
+	clear(p.buf[:1])
+	p.buf = p.buf[1:]
+	return v, true
+}
+
+// Peek returns the next value without consuming it. If there are no
+// more values, it returns false.
+func (p *Peekable[T]) Peek() (T, bool) {
+	if !p.fill(1) {
+		var zero T
+		return zero, false
+	}
+	return p.buf[0], true
+}
+
+// PeekN returns the next k values without consuming them. If there
+// are fewer than k values left, it returns all of them. The returned
+// slice is a copy and may be kept and modified freely.
+func (p *Peekable[T]) PeekN(k int) []T {
+	p.fill(k)
+	return slices.Clone(p.buf[:min(max(k, 0), len(p.buf))])
+}
+
+// fill pulls values into the buffer until it holds at least n of
+// them, returning false if there were not enough left.
+func (p *Peekable[T]) fill(n int) bool {
+	for len(p.buf) < n {
+		v, ok := p.next()
+		if !ok {
+			return false
+		}
+		p.buf = append(p.buf, v)
+	}
+	return true
+}
+
+// Unread pushes v back so that it is the next value returned. It may
+// be called repeatedly, in which case the values are returned in the
+// reverse order from which they were unread. v does not need to be a
+// value that was previously returned.
+func (p *Peekable[T]) Unread(v T) {
+	p.buf = slices.Insert(p.buf, 0, v)
+}
+
+// All returns a Seq that yields the remaining values of p, consuming
+// them as it does. Stopping iteration of the returned Seq early does
+// not stop p, so any values that were not yielded are left for
+// further use.
+func (p *Peekable[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			v, ok := p.Next()
+			if !ok || !yield(v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package xiter
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPeekable(t *testing.T) {
+	p := NewPeekable(Of(1, 2, 3, 4, 5))
+	defer p.Stop()
+
+	if v, ok := p.Peek(); v != 1 || !ok {
+		t.Fatal(v, ok)
+	}
+	if s := p.PeekN(3); !slices.Equal(s, []int{1, 2, 3}) {
+		t.Fatal(s)
+	}
+	if v, ok := p.Next(); v != 1 || !ok {
+		t.Fatal(v, ok)
+	}
+
+	p.Unread(1)
+	p.Unread(0)
+	if s := p.PeekN(10); !slices.Equal(s, []int{0, 1, 2, 3, 4, 5}) {
+		t.Fatal(s)
+	}
+
+	if s := slices.Collect(Limit(p.All(), 2)); !slices.Equal(s, []int{0, 1}) {
+		t.Fatal(s)
+	}
+	if s := slices.Collect(p.All()); !slices.Equal(s, []int{2, 3, 4, 5}) {
+		t.Fatal(s)
+	}
+	if v, ok := p.Next(); ok {
+		t.Fatal(v)
+	}
+	if v, ok := p.Peek(); ok {
+		t.Fatal(v)
+	}
+
+	p.Unread(1)
+	p.Stop()
+	p.Unread(2)
+	if s := slices.Collect(p.All()); !slices.Equal(s, []int{2}) {
+		t.Fatal(s)
+	}
+}
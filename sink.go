@@ -228,3 +228,31 @@ func StringJoin(seq iter.Seq[string], sep string) string {
 	}
 	return buf.String()
 }
+
+// IndexSubseq returns the index in seq of the first element of the
+// first run of consecutive elements equal to pattern, or -1 if there
+// is no such run. If pattern is empty, it returns 0. It uses the
+// Knuth-Morris-Pratt algorithm, so it only needs O(len(pattern))
+// memory regardless of the length of seq and stops iterating as soon
+// as a match is found.
+func IndexSubseq[T comparable](seq iter.Seq[T], pattern []T) int {
+	if len(pattern) == 0 {
+		return 0
+	}
+
+	table := prefixTable(pattern)
+	var j int
+	for i, v := range Enumerate(seq) {
+		for j > 0 && v != pattern[j] {
+			j = table[j-1]
+		}
+		if v != pattern[j] {
+			continue
+		}
+		j++
+		if j == len(pattern) {
+			return i - len(pattern) + 1
+		}
+	}
+	return -1
+}
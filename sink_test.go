@@ -122,3 +122,20 @@ func TestStringJoin(t *testing.T) {
 		t.Fatalf("%q", s)
 	}
 }
+
+func TestIndexSubseq(t *testing.T) {
+	i := IndexSubseq(Generate(0, 1), []int{5, 6, 7})
+	if i != 5 {
+		t.Fatal(i)
+	}
+
+	i = IndexSubseq(Of(1, 2, 1, 2, 1, 3), []int{1, 2, 1, 3})
+	if i != 2 {
+		t.Fatal(i)
+	}
+
+	i = IndexSubseq(Of(1, 2, 3), []int{2, 4})
+	if i != -1 {
+		t.Fatal(i)
+	}
+}
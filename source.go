@@ -1,9 +1,13 @@
 package xiter
 
 import (
+	"bytes"
+	"cmp"
 	"context"
+	"fmt"
 	"io"
 	"iter"
+	"maps"
 	"slices"
 	"strings"
 	"unicode"
@@ -45,7 +49,7 @@ func Bytes(s string) iter.Seq[byte] {
 // Runes returns a Seq over the runes of s.
 func Runes[T ~[]byte | ~string](s T) iter.Seq[rune] {
 	return func(yield func(rune) bool) {
-		b := unsafe.Slice(unsafe.StringData(*(*string)(unsafe.Pointer(&s))), len(s))
+		b := unsafe.Slice(unsafe.StringData(str(s)), len(s))
 		for len(b) > 0 {
 			r, size := utf8.DecodeRune(b)
 			if !yield(r) {
@@ -56,25 +60,158 @@ func Runes[T ~[]byte | ~string](s T) iter.Seq[rune] {
 	}
 }
 
+// str returns the contents of s as a string without copying. If s is
+// a byte slice, the returned string must not be used after s is
+// modified.
+func str[T ~[]byte | ~string](s T) string {
+	return *(*string)(unsafe.Pointer(&s))
+}
+
 // StringSplit returns an iterator over the substrings of s that are
 // separated by sep. It behaves very similarly to [strings.Split].
-func StringSplit(s, sep string) iter.Seq[string] {
-	if sep == "" {
-		return Map(Runes(s), func(c rune) string { return string(c) })
-	}
+//
+// Like the rest of the StringSplit family of functions, it works on
+// byte slices as well as strings. For byte slices, the yielded slices
+// are subslices of s, so no copying is done.
+func StringSplit[T ~[]byte | ~string](s, sep T) iter.Seq[T] {
+	return split(s, sep, 0, -1)
+}
 
-	return func(yield func(string) bool) {
-		for {
-			m := strings.Index(s, sep)
+// StringSplitN is like [StringSplit] but yields at most n substrings,
+// the last of which is the unsplit remainder of s. If n is 0, nothing
+// is yielded. If n is negative, there is no limit. It behaves very
+// similarly to [strings.SplitN].
+func StringSplitN[T ~[]byte | ~string](s, sep T, n int) iter.Seq[T] {
+	return split(s, sep, 0, n)
+}
+
+// StringSplitAfter is like [StringSplit] but includes sep at the end
+// of each yielded substring. It behaves very similarly to
+// [strings.SplitAfter].
+func StringSplitAfter[T ~[]byte | ~string](s, sep T) iter.Seq[T] {
+	return split(s, sep, len(sep), -1)
+}
+
+// StringSplitAfterN is like [StringSplitAfter] but limits the number
+// of yielded substrings in the same way as [StringSplitN].
+func StringSplitAfterN[T ~[]byte | ~string](s, sep T, n int) iter.Seq[T] {
+	return split(s, sep, len(sep), n)
+}
+
+// split implements the StringSplit family of functions. It is based
+// on genSplit from the strings package. The first sepSave bytes of
+// sep are included in the yielded substrings.
+func split[T ~[]byte | ~string](s, sep T, sepSave, n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if n == 0 {
+			return
+		}
+		if len(sep) == 0 {
+			explode(s, n)(yield)
+			return
+		}
+
+		s := s
+		for n := n; n != 1; n-- {
+			m := strings.Index(str(s), str(sep))
 			if m < 0 {
-				yield(s)
-				return
+				break
 			}
-			if !yield(s[:m]) {
+			if !yield(s[:m+sepSave]) {
 				return
 			}
 			s = s[m+len(sep):]
 		}
+		yield(s)
+	}
+}
+
+// explode yields the UTF-8 sequences of s one at a time, with the
+// last yielded substring containing the rest of s if n is reached.
+// Invalid UTF-8 is yielded one byte at a time.
+func explode[T ~[]byte | ~string](s T, n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s := s
+		for n := n; n != 1 && len(s) > 0; n-- {
+			_, size := utf8.DecodeRuneInString(str(s))
+			if !yield(s[:size]) {
+				return
+			}
+			s = s[size:]
+		}
+		if len(s) > 0 {
+			yield(s)
+		}
+	}
+}
+
+// StringLines returns an iterator over the lines of s with their
+// line terminators, either "\n" or "\r\n", removed. If s ends with a
+// line terminator, no empty final line is yielded. For byte slices,
+// the yielded slices are subslices of s.
+func StringLines[T ~[]byte | ~string](s T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for line := range StringLinesAfter(s) {
+			if len(line) > 0 && line[len(line)-1] == '\n' {
+				line = line[:len(line)-1]
+				if len(line) > 0 && line[len(line)-1] == '\r' {
+					line = line[:len(line)-1]
+				}
+			}
+			if !yield(line) {
+				return
+			}
+		}
+	}
+}
+
+// StringLinesAfter is like [StringLines] but leaves the line
+// terminators at the ends of the yielded lines.
+func StringLinesAfter[T ~[]byte | ~string](s T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s := s
+		for len(s) > 0 {
+			end := strings.IndexByte(str(s), '\n') + 1
+			if end == 0 {
+				end = len(s)
+			}
+			if !yield(s[:end]) {
+				return
+			}
+			s = s[end:]
+		}
+	}
+}
+
+// StringPairs returns an iterator over key/value pairs in s. It
+// splits s by sep and then splits each non-empty substring around
+// the first instance of kvsep in the same way as [strings.Cut],
+// yielding the text before and after it. If a substring does not
+// contain kvsep, the entire substring is yielded as the key with an
+// empty value. For example,
+//
+//	StringPairs("a=1&b&c=2=3", "&", "=")
+//
+// will yield
+//
+//	"a", "1"
+//	"b", ""
+//	"c", "2=3"
+func StringPairs[T ~[]byte | ~string](s, sep, kvsep T) iter.Seq2[T, T] {
+	return func(yield func(T, T) bool) {
+		for pair := range StringSplit(s, sep) {
+			if len(pair) == 0 {
+				continue
+			}
+
+			k, v := pair, pair[len(pair):]
+			if i := strings.Index(str(pair), str(kvsep)); i >= 0 {
+				k, v = pair[:i], pair[i+len(kvsep):]
+			}
+			if !yield(k, v) {
+				return
+			}
+		}
 	}
 }
 
@@ -91,13 +228,14 @@ func StringFields(s string) iter.Seq[string] {
 func StringFieldsFunc(s string, sep func(rune) bool) iter.Seq[string] {
 	return func(yield func(string) bool) {
 		start := 0
-		for i, r := range Enumerate(Runes(s)) {
+		for i, r := range s {
 			if !sep(r) {
 				continue
 			}
 
 			field := s[start:i]
-			start = i + 1
+			_, size := utf8.DecodeRuneInString(s[i:])
+			start = i + size
 			if field == "" {
 				continue
 			}
@@ -117,6 +255,137 @@ func StringFieldsFunc(s string, sep func(rune) bool) iter.Seq[string] {
 	}
 }
 
+// A SyntaxError is yielded by parsing functions, such as
+// [ShellFields], when their input is malformed.
+type SyntaxError struct {
+	// Offset is the byte offset in the input at which the problem
+	// was detected.
+	Offset int
+
+	// Msg describes the problem.
+	Msg string
+}
+
+func (err *SyntaxError) Error() string {
+	return fmt.Sprintf("%v at offset %v", err.Msg, err.Offset)
+}
+
+// ShellFields returns an iterator over the words of s split using the
+// quoting rules of the POSIX shell. Words are separated by spaces,
+// tabs and newlines unless they are quoted or escaped. Text in single
+// quotes is taken literally, while text in double quotes may contain
+// backslash escapes of '$', '`', '"', '\\' and newlines. Outside of
+// quotes, a backslash escapes any character, and a backslash followed
+// by a newline is removed entirely. A '#' at the start of a word
+// begins a comment that extends to the end of the line. Quote
+// removal is performed, but no expansions of any kind are.
+//
+// If s contains an unterminated quote or ends with an unescaped
+// backslash, a [*SyntaxError] is yielded and iteration ends.
+func ShellFields(s string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		var word strings.Builder
+		var inword bool
+		fail := func(off int, msg string) {
+			yield("", &SyntaxError{Offset: off, Msg: msg})
+		}
+
+		for i := 0; i < len(s); {
+			switch c := s[i]; c {
+			case ' ', '\t', '\n':
+				i++
+				if !inword {
+					continue
+				}
+				if !yield(word.String(), nil) {
+					return
+				}
+				word.Reset()
+				inword = false
+
+			case '#':
+				if inword {
+					word.WriteByte(c)
+					i++
+					continue
+				}
+				end := strings.IndexByte(s[i:], '\n')
+				if end < 0 {
+					return
+				}
+				i += end
+
+			case '\\':
+				if i+1 == len(s) {
+					fail(i, "trailing backslash")
+					return
+				}
+				if s[i+1] != '\n' {
+					word.WriteByte(s[i+1])
+					inword = true
+				}
+				i += 2
+
+			case '\'':
+				end := strings.IndexByte(s[i+1:], '\'')
+				if end < 0 {
+					fail(i, "unterminated single quote")
+					return
+				}
+				word.WriteString(s[i+1 : i+1+end])
+				inword = true
+				i += end + 2
+
+			case '"':
+				start := i
+				for i++; ; i++ {
+					if i == len(s) {
+						fail(start, "unterminated double quote")
+						return
+					}
+					if s[i] == '"' {
+						i++
+						break
+					}
+					if s[i] == '\\' && i+1 < len(s) {
+						switch s[i+1] {
+						case '$', '`', '"', '\\':
+							i++
+						case '\n':
+							i++
+							continue
+						}
+					}
+					word.WriteByte(s[i])
+				}
+				inword = true
+
+			default:
+				word.WriteByte(c)
+				inword = true
+				i++
+			}
+		}
+
+		if inword {
+			yield(word.String(), nil)
+		}
+	}
+}
+
+// SortedMap returns a Seq2 over the key/value pairs of m in
+// ascending order of key. Unlike ranging over m directly, the order
+// is the same every time.
+func SortedMap[M ~map[K]V, K cmp.Ordered, V any](m M) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, k := range slices.Sorted(maps.Keys(m)) {
+			if !yield(k, m[k]) {
+				return
+			}
+		}
+	}
+}
+
 // ToPair takes a two-value iterator and produces a single-value
 // iterator of pairs.
 func ToPair[T1, T2 any](seq iter.Seq2[T1, T2]) iter.Seq[Pair[T1, T2]] {
@@ -180,6 +449,17 @@ func RecvContext[T any](ctx context.Context, c <-chan T) iter.Seq[T] {
 // subslices of the underlying slice instead of having to allocate a
 // moving window. The yielded subslices have their capacity clipped.
 func SliceChunksFunc[T any, C comparable, S ~[]T](s S, chunker func(T) C) iter.Seq[S] {
+	return sliceChunksFunc(s, chunker, false)
+}
+
+// SliceChunksFuncCopy is like [SliceChunksFunc] but yields a newly
+// allocated copy of each chunk instead of a subslice of s, so that
+// modifying a chunk does not modify s or vice versa.
+func SliceChunksFuncCopy[T any, C comparable, S ~[]T](s S, chunker func(T) C) iter.Seq[S] {
+	return sliceChunksFunc(s, chunker, true)
+}
+
+func sliceChunksFunc[T any, C comparable, S ~[]T](s S, chunker func(T) C, clone bool) iter.Seq[S] {
 	return func(yield func(S) bool) {
 		if len(s) == 0 {
 			return
@@ -194,7 +474,7 @@ func SliceChunksFunc[T any, C comparable, S ~[]T](s S, chunker func(T) C) iter.S
 				continue
 			}
 
-			if !yield(slices.Clip(s[start:i])) {
+			if !yield(own(slices.Clip(s[start:i]), clone)) {
 				return
 			}
 			prev, start = cur, i
@@ -202,7 +482,7 @@ func SliceChunksFunc[T any, C comparable, S ~[]T](s S, chunker func(T) C) iter.S
 
 		last := s[start:]
 		if len(last) != 0 {
-			if !yield(slices.Clip(last)) {
+			if !yield(own(slices.Clip(last), clone)) {
 				return
 			}
 		}
@@ -213,6 +493,10 @@ func SliceChunksFunc[T any, C comparable, S ~[]T](s S, chunker func(T) C) iter.S
 // that function returns a non-nil error, the iterator will yield that
 // error and then exit. If the iterator is terminated early, it will
 // call the provided done function first.
+//
+// Like every reader-backed source in this package, the iterator
+// yields io.EOF when the end of the input is reached. [UntilEOF] can
+// be used to end the sequence cleanly instead.
 func reader[T byte | rune](read func() (T, error), done func()) iter.Seq2[T, error] {
 	return func(yield func(T, error) bool) {
 		for {
@@ -231,7 +515,8 @@ func reader[T byte | rune](read func() (T, error), done func()) iter.Seq2[T, err
 
 // ReadBytes returns an iterator over the bytes of r. If reading the
 // next byte returns an error, the iterator will yield a non-nil error
-// and then exit.
+// and then exit. Reaching the end of r yields [io.EOF]. To end the
+// sequence cleanly instead, wrap it with [UntilEOF].
 func ReadBytes(r io.ByteReader) iter.Seq2[byte, error] {
 	return reader(
 		r.ReadByte,
@@ -241,7 +526,8 @@ func ReadBytes(r io.ByteReader) iter.Seq2[byte, error] {
 
 // ReadRunes returns an iterator over the runes of r. If reading the
 // next rune returns an error, the iterator will yield a non-nil error
-// and then exit.
+// and then exit. Reaching the end of r yields [io.EOF]. To end the
+// sequence cleanly instead, wrap it with [UntilEOF].
 func ReadRunes(r io.RuneReader) iter.Seq2[rune, error] {
 	return reader(
 		func() (rune, error) {
@@ -254,7 +540,8 @@ func ReadRunes(r io.RuneReader) iter.Seq2[rune, error] {
 
 // ScanBytes returns an iterator over the bytes of r. If reading the
 // next byte returns an error, the iterator will yield a non-nil error
-// and then exit.
+// and then exit. Reaching the end of r yields [io.EOF]. To end the
+// sequence cleanly instead, wrap it with [UntilEOF].
 //
 // If the iterator is terminated early, it will unread
 // the last byte read, allowing it to be used again to continue from
@@ -269,7 +556,8 @@ func ScanBytes(r io.ByteScanner) iter.Seq2[byte, error] {
 
 // ScanRunes returns an iterator over the runes of r. If reading the
 // next rune returns an error, the iterator will yield a non-nil error
-// and then exit.
+// and then exit. Reaching the end of r yields [io.EOF]. To end the
+// sequence cleanly instead, wrap it with [UntilEOF].
 //
 // If the iterator is terminated early, it will unread
 // the last rune read, allowing it to be used again to continue from
@@ -284,3 +572,110 @@ func ScanRunes(r io.RuneScanner) iter.Seq2[rune, error] {
 		func() { r.UnreadRune() },
 	)
 }
+
+// blocks returns an iterator over successive blocks of at most size
+// bytes read using the given function, which is passed the offset
+// from the start of the iteration that the block begins at. The read
+// function should behave like [io.ReaderAt.ReadAt], returning io.EOF
+// alongside the final bytes of the input or on its own once the
+// input has been exhausted. If clone is false, the yielded slice is
+// reused.
+func blocks(read func([]byte, int64) (int, error), size int, clone bool) iter.Seq2[[]byte, error] {
+	if size <= 0 {
+		panic("xiter: non-positive block size")
+	}
+
+	return func(yield func([]byte, error) bool) {
+		buf := make([]byte, size)
+		var off int64
+		for {
+			n, err := read(buf, off)
+			off += int64(n)
+
+			block := buf[:n]
+			if clone {
+				block = bytes.Clone(block)
+			}
+
+			switch err {
+			case nil:
+				if !yield(block, nil) {
+					return
+				}
+			case io.EOF:
+				if n > 0 && !yield(block, nil) {
+					return
+				}
+				yield(nil, io.EOF)
+				return
+			default:
+				yield(block, err)
+				return
+			}
+		}
+	}
+}
+
+// ReadBlocks returns an iterator over successive blocks of size bytes
+// read from r, calling r.Read repeatedly until each block is full.
+// Every block is full except possibly for the last one, which may be
+// short if the length of the input is not a multiple of size. Once
+// the input has been exhausted, the iterator yields [io.EOF] and then
+// exits, so it can be used with [UntilEOF] like the other
+// reader-backed sources. Any other error, including
+// [io.ErrUnexpectedEOF] returned by r to signal truncated input, is
+// yielded unchanged along with whatever was read before it occurred.
+//
+// Like with [Chunks], the yielded slice is reused between iterations.
+// For a version that yields a new slice each time, see
+// [ReadBlocksCopy]. ReadBlocks panics if size is not positive.
+func ReadBlocks(r io.Reader, size int) iter.Seq2[[]byte, error] {
+	return blocks(readFull(r), size, false)
+}
+
+// ReadBlocksCopy is like [ReadBlocks] but yields a newly allocated
+// slice for every block, allowing the blocks to be held onto after
+// each iteration has ended.
+func ReadBlocksCopy(r io.Reader, size int) iter.Seq2[[]byte, error] {
+	return blocks(readFull(r), size, true)
+}
+
+// readFull reads from r until buf is full. Unlike [io.ReadFull], it
+// returns errors from r as they are, so an io.EOF partway through buf
+// is not turned into io.ErrUnexpectedEOF and an io.ErrUnexpectedEOF
+// from r is not mistaken for the end of the input.
+func readFull(r io.Reader) func([]byte, int64) (int, error) {
+	return func(buf []byte, _ int64) (int, error) {
+		var n int
+		for n < len(buf) {
+			nr, err := r.Read(buf[n:])
+			n += nr
+			if err != nil {
+				return n, err
+			}
+		}
+		return n, nil
+	}
+}
+
+// ReadAtBlocks is like [ReadBlocks] but reads from r starting at the
+// offset off using [io.ReaderAt.ReadAt]. Because it does not depend
+// on any state in r, several iterators may read different regions of
+// the same r concurrently, such as when splitting a large file
+// between workers. Unlike ReadBlocks, the returned iterator starts
+// over from off every time that it is used.
+func ReadAtBlocks(r io.ReaderAt, off int64, size int) iter.Seq2[[]byte, error] {
+	return blocks(readAt(r, off), size, false)
+}
+
+// ReadAtBlocksCopy is like [ReadAtBlocks] but yields a newly
+// allocated slice for every block.
+func ReadAtBlocksCopy(r io.ReaderAt, off int64, size int) iter.Seq2[[]byte, error] {
+	return blocks(readAt(r, off), size, true)
+}
+
+func readAt(r io.ReaderAt, start int64) func([]byte, int64) (int, error) {
+	return func(buf []byte, off int64) (int, error) {
+		return r.ReadAt(buf, start+off)
+	}
+}
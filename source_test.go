@@ -1,12 +1,16 @@
 package xiter
 
 import (
+	"bytes"
 	"cmp"
 	"context"
+	"errors"
+	"io"
 	"maps"
 	"slices"
 	"strings"
 	"testing"
+	"testing/iotest"
 	"unicode"
 )
 
@@ -52,6 +56,55 @@ func TestStringSplit(t *testing.T) {
 	}
 }
 
+func TestStringSplitN(t *testing.T) {
+	tests := []struct {
+		s, sep string
+		n      int
+	}{
+		{"a,b,c,d", ",", 2},
+		{"a,b,c,d", ",", -1},
+		{"a,b,c,d", ",", 0},
+		{"a,b,", ",", 5},
+		{"テスト", "", 2},
+		{"", ",", -1},
+	}
+	for _, test := range tests {
+		s := slices.Collect(StringSplitN(test.s, test.sep, test.n))
+		if check := strings.SplitN(test.s, test.sep, test.n); !slices.Equal(s, check) {
+			t.Errorf("%q, %q, %v: %q != %q", test.s, test.sep, test.n, s, check)
+		}
+
+		s = slices.Collect(StringSplitAfterN(test.s, test.sep, test.n))
+		if check := strings.SplitAfterN(test.s, test.sep, test.n); !slices.Equal(s, check) {
+			t.Errorf("%q, %q, %v: %q != %q", test.s, test.sep, test.n, s, check)
+		}
+	}
+
+	b := slices.Collect(StringSplitAfter([]byte("a\r\n\r\nb"), []byte("\r\n")))
+	if !slices.EqualFunc(b, [][]byte{[]byte("a\r\n"), []byte("\r\n"), []byte("b")}, bytes.Equal) {
+		t.Fatalf("%q", b)
+	}
+}
+
+func TestStringLines(t *testing.T) {
+	s := slices.Collect(StringLines("one\ntwo\r\n\nthree\n"))
+	if !slices.Equal(s, []string{"one", "two", "", "three"}) {
+		t.Fatalf("%q", s)
+	}
+
+	b := slices.Collect(StringLinesAfter([]byte("one\ntwo")))
+	if !slices.EqualFunc(b, [][]byte{[]byte("one\n"), []byte("two")}, bytes.Equal) {
+		t.Fatalf("%q", b)
+	}
+}
+
+func TestStringPairs(t *testing.T) {
+	s := slices.Collect(ToPair(StringPairs("a=1&b&&c=2=3", "&", "=")))
+	if !slices.Equal(s, []Pair[string, string]{{"a", "1"}, {"b", ""}, {"c", "2=3"}}) {
+		t.Fatalf("%q", s)
+	}
+}
+
 func TestStringFields(t *testing.T) {
 	s := slices.Collect(StringFields("  this is a  test "))
 	if !slices.Equal(s, []string{"this", "is", "a", "test"}) {
@@ -62,6 +115,32 @@ func TestStringFields(t *testing.T) {
 	if !slices.Equal(s, []string{"this", "is", "a", "test"}) {
 		t.Fatal(s)
 	}
+
+	s = slices.Collect(StringFields("これは\u3000テスト です"))
+	if !slices.Equal(s, []string{"これは", "テスト", "です"}) {
+		t.Fatal(s)
+	}
+}
+
+func TestShellFields(t *testing.T) {
+	var s []string
+	for word, err := range ShellFields(`cmd -a 'it''s here' "say \"hi\" \$x" a\ b \
+c# d # comment
+''  last`) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		s = append(s, word)
+	}
+	if !slices.Equal(s, []string{"cmd", "-a", "its here", `say "hi" $x`, "a b", "c#", "d", "", "last"}) {
+		t.Fatalf("%q", s)
+	}
+
+	errs := slices.Collect(V2(ShellFields(`ok "unterminated`)))
+	var serr *SyntaxError
+	if len(errs) != 2 || !errors.As(errs[1], &serr) || serr.Offset != 3 {
+		t.Fatal(errs)
+	}
 }
 
 func TestSliceChunkBy(t *testing.T) {
@@ -92,3 +171,42 @@ func TestScanBytes(t *testing.T) {
 		t.Fatalf("%q, %v", c, err)
 	}
 }
+
+func TestReadBlocks(t *testing.T) {
+	var blocks []string
+	for b, err := range UntilEOF(ReadBlocks(strings.NewReader("this is a test"), 4)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		blocks = append(blocks, string(b))
+	}
+	if !slices.Equal(blocks, []string{"this", " is ", "a te", "st"}) {
+		t.Fatal(blocks)
+	}
+
+	s := slices.Collect(V1(ReadBlocksCopy(strings.NewReader("abcdef"), 3)))
+	if !slices.EqualFunc(s, [][]byte{[]byte("abc"), []byte("def"), nil}, bytes.Equal) {
+		t.Fatal(s)
+	}
+	truncated := io.MultiReader(strings.NewReader("abcde"), iotest.ErrReader(io.ErrUnexpectedEOF))
+	var errs []error
+	blocks = nil
+	for b, err := range ReadBlocks(truncated, 4) {
+		blocks = append(blocks, string(b))
+		errs = append(errs, err)
+	}
+	if !slices.Equal(blocks, []string{"abcd", "e"}) || !slices.Equal(errs, []error{nil, io.ErrUnexpectedEOF}) {
+		t.Fatal(blocks, errs)
+	}
+}
+
+func TestReadAtBlocks(t *testing.T) {
+	r := strings.NewReader("this is a test")
+	seq := ReadAtBlocksCopy(r, 5, 4)
+	for range 2 {
+		s := slices.Collect(V1(UntilEOF(seq)))
+		if !slices.EqualFunc(s, [][]byte{[]byte("is a"), []byte(" tes"), []byte("t")}, bytes.Equal) {
+			t.Fatal(s)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package xiter
+
+import (
+	"errors"
+	"iter"
+	"sync"
+)
+
+// ErrTeeFull is yielded by a Seq2 returned from [TeeLimit] with the
+// [TeeFail] policy when yielding another value would require
+// buffering more than the limit.
+var ErrTeeFull = errors.New("tee buffer full")
+
+// TeePolicy determines what a Seq2 returned from [TeeLimit] does when
+// it gets too far ahead of the others.
+type TeePolicy int
+
+const (
+	// TeeBlock blocks until the slowest of the other Seqs catches up
+	// enough for buffering to be possible again. Because the slowest
+	// Seq must make progress for this to happen, it must be iterated
+	// concurrently or the blocked one will deadlock.
+	TeeBlock TeePolicy = iota
+
+	// TeeFail yields [ErrTeeFull] and then ends iteration.
+	TeeFail
+)
+
+// Tee returns n Seqs that each yield all of the values of seq while
+// only iterating over seq once. The returned Seqs may be iterated in
+// any interleaving, including concurrently, and only the values that
+// the fastest of them has yielded but the slowest has not are
+// buffered. Note that a Seq that has not started iterating yet is the
+// slowest possible, so values are buffered on its behalf until it is
+// either iterated or until it has been iterated and stopped.
+//
+// Each of the returned Seqs may only be iterated once. seq is not
+// iterated until one of them is and it is stopped when all of them
+// have stopped. If n is not positive, Tee returns nil.
+func Tee[T any](seq iter.Seq[T], n int) []iter.Seq[T] {
+	if n <= 0 {
+		return nil
+	}
+
+	t := newTee(seq, n, 0, TeeBlock)
+	seqs := make([]iter.Seq[T], n)
+	for i := range seqs {
+		seqs[i] = func(yield func(T) bool) {
+			t.consume(i, func(v T, err error) bool { return yield(v) })
+		}
+	}
+	return seqs
+}
+
+// TeeLimit is like [Tee] but buffers at most limit values. If one of
+// the returned Seqs gets so far ahead of the slowest one that it
+// would need to buffer more than that, policy determines what
+// happens. Unless policy is [TeeFail], the yielded errors are always
+// nil. TeeLimit panics if limit is not positive.
+func TeeLimit[T any](seq iter.Seq[T], n, limit int, policy TeePolicy) []iter.Seq2[T, error] {
+	if limit <= 0 {
+		panic("xiter: non-positive tee limit")
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	t := newTee(seq, n, limit, policy)
+	seqs := make([]iter.Seq2[T, error], n)
+	for i := range seqs {
+		seqs[i] = func(yield func(T, error) bool) {
+			t.consume(i, yield)
+		}
+	}
+	return seqs
+}
+
+// tee is the state shared by the Seqs returned by Tee and TeeLimit.
+type tee[T any] struct {
+	m    sync.Mutex
+	cond sync.Cond
+
+	seq       iter.Seq[T]
+	next      func() (T, bool)
+	stop      func()
+	exhausted bool
+
+	limit  int
+	policy TeePolicy
+
+	// pos holds the index in seq of the next value for each consumer,
+	// or teeStopped if it has stopped, and buf holds the values that
+	// have been pulled from seq but not yet yielded by every consumer,
+	// the first of which is at index start.
+	consumers int
+	pos       []int
+	buf       []T
+	start     int
+}
+
+const teeStopped = -1
+
+func newTee[T any](seq iter.Seq[T], n, limit int, policy TeePolicy) *tee[T] {
+	t := tee[T]{
+		seq:       seq,
+		limit:     limit,
+		policy:    policy,
+		consumers: n,
+		pos:       make([]int, n),
+	}
+	t.cond.L = &t.m
+	return &t
+}
+
+// consume yields the values of t on behalf of the ith consumer.
+func (t *tee[T]) consume(i int, yield func(T, error) bool) {
+	defer t.finish(i)
+
+	for {
+		v, ok, err := t.get(i)
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+		if !ok || !yield(v, nil) {
+			return
+		}
+	}
+}
+
+// get returns the next value for the ith consumer.
+func (t *tee[T]) get(i int) (v T, ok bool, err error) {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	for {
+		pos := t.pos[i]
+		if pos == teeStopped {
+			return v, false, nil
+		}
+
+		if pos < t.start+len(t.buf) {
+			v = t.buf[pos-t.start]
+			t.pos[i]++
+			t.trim()
+			return v, true, nil
+		}
+		if t.exhausted {
+			return v, false, nil
+		}
+
+		if t.limit > 0 && len(t.buf) >= t.limit {
+			if t.policy == TeeFail {
+				return v, false, ErrTeeFull
+			}
+			t.cond.Wait()
+			continue
+		}
+
+		if t.next == nil {
+			t.next, t.stop = iter.Pull(t.seq)
+		}
+		next, ok := t.next()
+		if !ok {
+			t.exhausted = true
+			continue
+		}
+		t.buf = append(t.buf, next)
+	}
+}
+
+// trim removes values from the buffer that every consumer has
+// already yielded and wakes up any consumers waiting for space.
+// t.m must be held.
+func (t *tee[T]) trim() {
+	start := t.start + len(t.buf)
+	for _, pos := range t.pos {
+		if pos != teeStopped {
+			start = min(start, pos)
+		}
+	}
+
+	n := start - t.start
+	if n == 0 {
+		return
+	}
+	clear(t.buf[:n])
+	t.buf = t.buf[n:]
+	t.start = start
+	t.cond.Broadcast()
+}
+
+// finish marks the ith consumer as stopped, stopping seq if it was
+// the last one.
+func (t *tee[T]) finish(i int) {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	if t.pos[i] == teeStopped {
+		return
+	}
+	t.pos[i] = teeStopped
+	t.trim()
+
+	t.consumers--
+	if t.consumers == 0 && t.stop != nil {
+		t.stop()
+	}
+}
@@ -0,0 +1,89 @@
+package xiter
+
+import (
+	"iter"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestTee(t *testing.T) {
+	var pulls int
+	seq := func(yield func(int) bool) {
+		for i := range 5 {
+			pulls++
+			if !yield(i) {
+				return
+			}
+		}
+	}
+
+	seqs := Tee(seq, 3)
+	a := slices.Collect(seqs[0])
+	b := slices.Collect(Limit(seqs[1], 2))
+	c := slices.Collect(seqs[2])
+	if !slices.Equal(a, []int{0, 1, 2, 3, 4}) || !slices.Equal(b, []int{0, 1}) || !slices.Equal(c, a) {
+		t.Fatal(a, b, c)
+	}
+	if pulls != 5 {
+		t.Fatal(pulls)
+	}
+
+	if s := slices.Collect(seqs[0]); len(s) != 0 {
+		t.Fatal(s)
+	}
+}
+
+func TestTeeInterleaved(t *testing.T) {
+	seqs := Tee(Generate(0, 1), 2)
+	n1, stop1 := iter.Pull(seqs[0])
+	defer stop1()
+	n2, stop2 := iter.Pull(seqs[1])
+	defer stop2()
+
+	for i := range 10 {
+		v1, _ := n1()
+		v1, _ = n1()
+		v2, _ := n2()
+		if v1 != 2*i+1 || v2 != i {
+			t.Fatal(i, v1, v2)
+		}
+	}
+}
+
+func TestTeeLimit(t *testing.T) {
+	seqs := TeeLimit(Generate(0, 1), 2, 3, TeeFail)
+	var s []int
+	var err error
+	for v, e := range seqs[0] {
+		if e != nil {
+			err = e
+			break
+		}
+		s = append(s, v)
+	}
+	if err != ErrTeeFull || !slices.Equal(s, []int{0, 1, 2}) {
+		t.Fatal(s, err)
+	}
+
+	seqs = TeeLimit(Limit(Generate(0, 1), 1000), 4, 2, TeeBlock)
+	sums := make([]int, len(seqs))
+	var wg sync.WaitGroup
+	for i, seq := range seqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for v, err := range seq {
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				sums[i] += v
+			}
+		}()
+	}
+	wg.Wait()
+	if !slices.Equal(sums, []int{499500, 499500, 499500, 499500}) {
+		t.Fatal(sums)
+	}
+}
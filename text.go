@@ -0,0 +1,435 @@
+package xiter
+
+import (
+	"iter"
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A Wrapper packs words into lines of limited width. Widths are
+// measured in runes.
+type Wrapper struct {
+	// Width is the maximum width of each line, including its
+	// indentation.
+	Width int
+
+	// Indent is placed at the start of the first line.
+	Indent string
+
+	// Hang is placed at the start of every line after the first,
+	// allowing for hanging indents.
+	Hang string
+
+	// Break causes words that are too long to fit on a line by
+	// themselves to be broken into pieces that do. Otherwise, such
+	// words are placed on a line by themselves that exceeds Width.
+	Break bool
+
+	// Balance causes lines to be chosen so as to minimize the
+	// raggedness of the right edge of the text, measured as the sum
+	// of the squares of the unused space at the end of every line but
+	// the last, as in Knuth's line breaking algorithm. This requires
+	// collecting every word before yielding any lines. Otherwise,
+	// words are packed greedily, placing as many on each line as will
+	// fit.
+	Balance bool
+}
+
+// Wrap is a convenience function that wraps words into lines of the
+// given width using a Wrapper with only Width set.
+func Wrap(words iter.Seq[string], width int) iter.Seq[string] {
+	return Wrapper{Width: width}.Wrap(words)
+}
+
+// Wrap returns a Seq over the lines produced by packing words,
+// separated by single spaces, into lines according to the settings of
+// w. Empty words are ignored. The words are usually obtained from
+// [StringFields].
+func (w Wrapper) Wrap(words iter.Seq[string]) iter.Seq[string] {
+	first := max(w.Width-utf8.RuneCountInString(w.Indent), 1)
+	rest := max(w.Width-utf8.RuneCountInString(w.Hang), 1)
+
+	words = Filter(words, func(word string) bool { return word != "" })
+	if w.Break {
+		words = breakWords(words, min(first, rest))
+	}
+	if w.Balance {
+		return w.balanced(words, first, rest)
+	}
+	return w.greedy(words, first, rest)
+}
+
+func (w Wrapper) greedy(words iter.Seq[string], first, rest int) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		var line strings.Builder
+		var n int
+		indent, width := w.Indent, first
+		for word := range words {
+			size := utf8.RuneCountInString(word)
+			if n > 0 && n+1+size > width {
+				if !yield(line.String()) {
+					return
+				}
+				line.Reset()
+				n = 0
+				indent, width = w.Hang, rest
+			}
+
+			if n == 0 {
+				line.WriteString(indent)
+			} else {
+				line.WriteByte(' ')
+				n++
+			}
+			line.WriteString(word)
+			n += size
+		}
+
+		if n > 0 {
+			yield(line.String())
+		}
+	}
+}
+
+func (w Wrapper) balanced(words iter.Seq[string], first, rest int) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		ws := slices.Collect(words)
+		sizes := slices.Collect(Map(slices.Values(ws), utf8.RuneCountInString))
+
+		// cost[i] is the minimum cost of laying out ws[i:] and ends[i]
+		// is the end of the line starting at ws[i] in that layout.
+		cost := make([]int, len(ws)+1)
+		ends := make([]int, len(ws))
+		for i := len(ws) - 1; i >= 0; i-- {
+			width := rest
+			if i == 0 {
+				width = first
+			}
+
+			cost[i] = math.MaxInt
+			n := -1
+			for j := i; j < len(ws); j++ {
+				n += 1 + sizes[j]
+				if n > width && j > i {
+					break
+				}
+
+				c := cost[j+1]
+				if j < len(ws)-1 && n < width {
+					c += (width - n) * (width - n)
+				}
+				if c < cost[i] {
+					cost[i], ends[i] = c, j+1
+				}
+			}
+		}
+
+		indent := w.Indent
+		for i := 0; i < len(ws); i = ends[i] {
+			if !yield(indent + strings.Join(ws[i:ends[i]], " ")) {
+				return
+			}
+			indent = w.Hang
+		}
+	}
+}
+
+// breakWords yields the words of seq, breaking those that are longer
+// than size runes into pieces of size runes.
+func breakWords(words iter.Seq[string], size int) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for word := range words {
+			for utf8.RuneCountInString(word) > size {
+				var end int
+				for range size {
+					_, n := utf8.DecodeRuneInString(word[end:])
+					end += n
+				}
+				if !yield(word[:end]) {
+					return
+				}
+				word = word[end:]
+			}
+			if !yield(word) {
+				return
+			}
+		}
+	}
+}
+
+// Shingles returns a Seq over every substring of s that is k runes
+// long, in order, such that each one starts one rune after the
+// previous. If s contains fewer than k runes, nothing is yielded.
+// The yielded strings are substrings of s, so no copying is done.
+func Shingles(s string, k int) iter.Seq[string] {
+	if k <= 0 {
+		return Of[string]()
+	}
+
+	// Windows of the offsets of the runes of s, plus the end of s,
+	// mark the boundaries of the shingles.
+	offsets := Concat(
+		func(yield func(int) bool) {
+			for i := range s {
+				if !yield(i) {
+					return
+				}
+			}
+		},
+		Of(len(s)),
+	)
+	return Map(NGrams(offsets, k+1), func(bounds []int) string {
+		return s[bounds[0]:bounds[k]]
+	})
+}
+
+// WordShingles is like [Shingles] but each shingle is made up of k
+// consecutive words of s, as determined by [StringFields], joined by
+// single spaces.
+func WordShingles(s string, k int) iter.Seq[string] {
+	return Map(NGrams(StringFields(s), k), func(words []string) string {
+		return strings.Join(words, " ")
+	})
+}
+
+// HashShingles returns a Seq over hashes of the shingles of s that
+// would be yielded by [Shingles], computed with [RollingHashes]
+// without creating the shingles themselves.
+func HashShingles(s string, k int) iter.Seq[uint64] {
+	return RollingHashes(Runes(s), k, func(r rune) uint64 { return uint64(r) })
+}
+
+// DefaultAbbreviations is the list of abbreviations used by a
+// [Segmenter] that does not specify its own.
+var DefaultAbbreviations = []string{
+	"mr", "mrs", "ms", "dr", "prof", "sr", "jr", "st", "mt", "vs",
+	"etc", "e.g", "i.e", "cf", "al", "approx", "dept", "est", "fig",
+	"inc", "ltd", "co", "corp", "jan", "feb", "mar", "apr", "jun",
+	"jul", "aug", "sep", "sept", "oct", "nov", "dec", "a.m", "p.m",
+	"u.s", "u.k",
+}
+
+// A Segmenter splits text into words and sentences using a simple set
+// of rules. It handles contractions, numbers containing separators,
+// abbreviations, initials and text in Chinese and Japanese, which is
+// not separated by spaces, but it makes no attempt to implement the
+// full Unicode text segmentation algorithm.
+type Segmenter struct {
+	// Abbreviations is a list of abbreviations, without their final
+	// period, which do not end a sentence when followed by one.
+	// Abbreviations are matched case-insensitively. If Abbreviations
+	// is nil, DefaultAbbreviations is used.
+	Abbreviations []string
+}
+
+// Words is a convenience function that calls Words on a zero
+// Segmenter.
+func Words(s string) iter.Seq[string] {
+	return Segmenter{}.Words(s)
+}
+
+// Sentences is a convenience function that calls Sentences on a zero
+// Segmenter.
+func Sentences(s string) iter.Seq[string] {
+	return Segmenter{}.Sentences(s)
+}
+
+func (sg Segmenter) abbreviations() map[string]struct{} {
+	list := sg.Abbreviations
+	if list == nil {
+		list = DefaultAbbreviations
+	}
+
+	abbrs := make(map[string]struct{}, len(list))
+	for _, abbr := range list {
+		abbrs[strings.ToLower(abbr)] = struct{}{}
+	}
+	return abbrs
+}
+
+// Words returns a Seq over the words of s. A word is a run of
+// letters and digits, which may contain apostrophes between letters,
+// as in "don't", periods between single letters, as in "e.g", and
+// periods and commas between digits, as in "1,000.5". A known
+// abbreviation includes its final period. Every Chinese or Japanese
+// character is yielded as a word by itself. Whitespace and other
+// punctuation are skipped. The yielded strings are substrings of s,
+// so no copying is done.
+func (sg Segmenter) Words(s string) iter.Seq[string] {
+	abbrs := sg.abbreviations()
+	return func(yield func(string) bool) {
+		for i := 0; i < len(s); {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			switch {
+			case isIdeographic(r):
+				if !yield(s[i : i+size]) {
+					return
+				}
+				i += size
+
+			case isWordRune(r):
+				end := wordEnd(s, i)
+				if end < len(s) && s[end] == '.' {
+					if _, ok := abbrs[strings.ToLower(s[i:end])]; ok {
+						end++
+					}
+				}
+				if !yield(s[i:end]) {
+					return
+				}
+				i = end
+
+			default:
+				i += size
+			}
+		}
+	}
+}
+
+func isIdeographic(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
+
+func isWordRune(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)) && !isIdeographic(r)
+}
+
+// wordEnd returns the end of the word starting at start in s.
+func wordEnd(s string, start int) int {
+	// seg is the start of the current segment of the word since the
+	// last period.
+	seg := start
+	var prev rune
+	for i := start; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if isWordRune(r) {
+			prev = r
+			i += size
+			continue
+		}
+
+		next, nsize := utf8.DecodeRuneInString(s[i+size:])
+		if nsize == 0 || !isWordRune(next) {
+			return i
+		}
+
+		var join bool
+		switch r {
+		case '\'', '’':
+			join = unicode.IsLetter(prev) && unicode.IsLetter(next)
+		case ',':
+			join = unicode.IsDigit(prev) && unicode.IsDigit(next)
+		case '.':
+			join = (unicode.IsDigit(prev) && unicode.IsDigit(next)) ||
+				(unicode.IsLetter(prev) && unicode.IsLetter(next) && utf8.RuneCountInString(s[seg:i]) == 1)
+		}
+		if !join {
+			return i
+		}
+
+		prev = r
+		i += size
+		if r == '.' {
+			seg = i
+		}
+	}
+	return len(s)
+}
+
+// Sentences returns a Seq over the sentences of s. A sentence ends
+// with a period, question mark or exclamation point, optionally
+// followed by more of them and by closing quotes and brackets, that
+// is in turn followed by whitespace or the end of s. A period does
+// not end a sentence if it follows a known abbreviation or a single
+// uppercase letter, as in an initial, and no punctuation ends a
+// sentence if the next sentence would start with a lowercase letter.
+// The Chinese and Japanese full stop, question mark and exclamation
+// point always end a sentence. Surrounding whitespace is trimmed from
+// the yielded sentences, which are substrings of s.
+func (sg Segmenter) Sentences(s string) iter.Seq[string] {
+	abbrs := sg.abbreviations()
+	return func(yield func(string) bool) {
+		var start int
+		for i := 0; i < len(s); {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			term := i
+			i += size
+			if !isTerminal(r) {
+				continue
+			}
+
+			for i < len(s) {
+				r, size := utf8.DecodeRuneInString(s[i:])
+				if !isTerminal(r) && !isCloser(r) {
+					break
+				}
+				i += size
+			}
+			if !sentenceEnds(s, term, i, abbrs) {
+				continue
+			}
+
+			sentence := strings.TrimSpace(s[start:i])
+			if sentence != "" && !yield(sentence) {
+				return
+			}
+			start = i
+		}
+
+		sentence := strings.TrimSpace(s[start:])
+		if sentence != "" {
+			yield(sentence)
+		}
+	}
+}
+
+func isTerminal(r rune) bool {
+	switch r {
+	case '.', '!', '?', '。', '！', '？':
+		return true
+	}
+	return false
+}
+
+func isCloser(r rune) bool {
+	switch r {
+	case '"', '\'', ')', ']', '}', '”', '’', '」', '』', '）':
+		return true
+	}
+	return false
+}
+
+// sentenceEnds reports whether the terminal punctuation at term,
+// along with anything following it up to end, ends a sentence.
+func sentenceEnds(s string, term, end int, abbrs map[string]struct{}) bool {
+	r, _ := utf8.DecodeRuneInString(s[term:])
+	switch {
+	case r == '。' || r == '！' || r == '？':
+		return true
+	case end == len(s):
+		return true
+	case !startsWithSpace(s[end:]):
+		return false
+	}
+
+	if r == '.' {
+		word := s[strings.LastIndexFunc(s[:term], func(r rune) bool { return !unicode.IsLetter(r) && r != '.' })+1 : term]
+		if _, ok := abbrs[strings.ToLower(word)]; ok {
+			return false
+		}
+		if r, size := utf8.DecodeRuneInString(word); size == len(word) && unicode.IsUpper(r) {
+			return false
+		}
+	}
+
+	next, _ := utf8.DecodeRuneInString(strings.TrimLeftFunc(s[end:], unicode.IsSpace))
+	return !unicode.IsLower(next)
+}
+
+func startsWithSpace(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsSpace(r)
+}
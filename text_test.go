@@ -0,0 +1,71 @@
+package xiter
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	words := StringFields("aaa bb cc ddddd")
+	s := slices.Collect(Wrap(words, 6))
+	if !slices.Equal(s, []string{"aaa bb", "cc", "ddddd"}) {
+		t.Fatalf("%q", s)
+	}
+
+	w := Wrapper{Width: 6, Balance: true}
+	s = slices.Collect(w.Wrap(words))
+	if !slices.Equal(s, []string{"aaa", "bb cc", "ddddd"}) {
+		t.Fatalf("%q", s)
+	}
+
+	w = Wrapper{Width: 8, Indent: "- ", Hang: "  ", Break: true}
+	s = slices.Collect(w.Wrap(StringFields("wrapping テキスト is verylongword")))
+	if !slices.Equal(s, []string{"- wrappi", "  ng", "  テキスト", "  is", "  verylo", "  ngword"}) {
+		t.Fatalf("%q", s)
+	}
+}
+
+func TestShingles(t *testing.T) {
+	s := slices.Collect(Shingles("テスト!", 2))
+	if !slices.Equal(s, []string{"テス", "スト", "ト!"}) {
+		t.Fatalf("%q", s)
+	}
+
+	s = slices.Collect(WordShingles("the quick  brown fox", 3))
+	if !slices.Equal(s, []string{"the quick brown", "quick brown fox"}) {
+		t.Fatalf("%q", s)
+	}
+
+	h := slices.Collect(HashShingles("abcab", 2))
+	if len(h) != 4 || h[0] != h[3] {
+		t.Fatal(h)
+	}
+}
+
+func TestWords(t *testing.T) {
+	s := slices.Collect(Words(`"Don't panic," said Dr. Smith, e.g. about the 1,000.5 items... 日本語です。`))
+	expected := []string{"Don't", "panic", "said", "Dr.", "Smith", "e.g.", "about", "the", "1,000.5", "items", "日", "本", "語", "で", "す"}
+	if !slices.Equal(s, expected) {
+		t.Fatalf("%q", s)
+	}
+}
+
+func TestSentences(t *testing.T) {
+	s := slices.Collect(Sentences(`Mr. J. Smith paid $3.50 for it. "Really?!" she asked.  It cost approx. five dollars, i.e. a lot... but fine. 日本語です。次の文`))
+	expected := []string{
+		"Mr. J. Smith paid $3.50 for it.",
+		`"Really?!" she asked.`,
+		"It cost approx. five dollars, i.e. a lot... but fine.",
+		"日本語です。",
+		"次の文",
+	}
+	if !slices.Equal(s, expected) {
+		t.Fatalf("%q", s)
+	}
+
+	sg := Segmenter{Abbreviations: []string{}}
+	s = slices.Collect(sg.Sentences("Call Dr. Who now."))
+	if !slices.Equal(s, []string{"Call Dr.", "Who now."}) {
+		t.Fatalf("%q", s)
+	}
+}
@@ -2,6 +2,8 @@ package xiter
 
 import (
 	"cmp"
+	"errors"
+	"io"
 	"iter"
 	"slices"
 
@@ -31,9 +33,11 @@ func Filter[T any](seq iter.Seq[T], f func(T) bool) iter.Seq[T] {
 }
 
 // Skip returns a Seq that skips over the first n elements of seq and
-// then yields the rest normally.
+// then yields the rest normally. If n is not positive, all of seq is
+// yielded.
 func Skip[T any](seq iter.Seq[T], n int) iter.Seq[T] {
 	return func(yield func(T) bool) {
+		n := n
 		seq(func(v T) bool {
 			if n > 0 {
 				n--
@@ -44,6 +48,51 @@ func Skip[T any](seq iter.Seq[T], n int) iter.Seq[T] {
 	}
 }
 
+// DropWhile returns a Seq that skips over elements of seq for as long
+// as f returns true for them and then yields the rest normally,
+// starting with the first element for which f returned false. Once
+// that element has been found, f is not called again.
+func DropWhile[T any](seq iter.Seq[T], f func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		dropping := true
+		seq(func(v T) bool {
+			if dropping {
+				if f(v) {
+					return true
+				}
+				dropping = false
+			}
+			return yield(v)
+		})
+	}
+}
+
+// DropLast returns a Seq that yields all but the last n elements of
+// seq. Elements are yielded n elements behind seq, so at most n
+// elements are buffered at a time. If n is not positive, all of seq
+// is yielded.
+func DropLast[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	if n <= 0 {
+		return seq
+	}
+
+	return func(yield func(T) bool) {
+		var ring []T
+		var i int
+		seq(func(v T) bool {
+			if len(ring) < n {
+				ring = append(ring, v)
+				return true
+			}
+
+			old := ring[i]
+			ring[i] = v
+			i = (i + 1) % n
+			return yield(old)
+		})
+	}
+}
+
 // Handle splits seq by calling f for any non-nil errors yielded by
 // seq. If f returns false, iteration stops. If an iteration's error
 // is nil or f returns true, the other value is yielded by the
@@ -63,9 +112,39 @@ func Handle[T any](seq iter.Seq2[T, error], f func(error) bool) iter.Seq[T] {
 	}
 }
 
-// Limit returns a Seq that yields at most n values from seq.
+// UntilEOF returns a Seq2 that yields the values of seq until seq
+// yields [io.EOF], at which point it ends cleanly without yielding
+// it. All other errors, including [io.ErrUnexpectedEOF], are yielded
+// normally. It can be used with any of the reader-backed sources in
+// this package, such as [ReadBytes] and [ScanRunes], so that only
+// real errors need to be handled by the caller:
+//
+//	for c, err := range UntilEOF(ReadBytes(r)) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func UntilEOF[T any](seq iter.Seq2[T, error]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		seq(func(v T, err error) bool {
+			if errors.Is(err, io.EOF) {
+				return false
+			}
+			return yield(v, err)
+		})
+	}
+}
+
+// Limit returns a Seq that yields at most n values from seq. If n is
+// not positive, nothing is yielded and seq is never iterated.
 func Limit[T any](seq iter.Seq[T], n int) iter.Seq[T] {
 	return func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+
+		n := n
 		seq(func(v T) bool {
 			if !yield(v) {
 				return false
@@ -76,6 +155,80 @@ func Limit[T any](seq iter.Seq[T], n int) iter.Seq[T] {
 	}
 }
 
+// TakeWhile returns a Seq that yields elements of seq for as long as
+// f returns true for them. Iteration of seq stops at the first
+// element for which f returns false, which is not yielded.
+func TakeWhile[T any](seq iter.Seq[T], f func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		seq(func(v T) bool {
+			return f(v) && yield(v)
+		})
+	}
+}
+
+// TakeLast returns a Seq that yields the last n elements of seq, or
+// all of them if there are fewer than n. Nothing can be yielded until
+// seq is exhausted, so at most n elements are buffered while it is
+// iterated. If n is not positive, nothing is yielded and seq is never
+// iterated.
+func TakeLast[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+
+		var ring []T
+		var i int
+		for v := range seq {
+			if len(ring) < n {
+				ring = append(ring, v)
+				continue
+			}
+			ring[i] = v
+			i = (i + 1) % n
+		}
+
+		if !yieldAll(ring[i:], yield) {
+			return
+		}
+		yieldAll(ring[:i], yield)
+	}
+}
+
+// Slice returns a Seq that yields the elements of seq with indices
+// from start up to but not including end, skipping step-1 elements
+// between each, in the manner of Python's slice notation. Unlike
+// Python's, negative indices do not count from the end of seq. A
+// negative start is treated as zero, and if end is not greater than
+// start, nothing is yielded. To slice through to the end of seq, pass
+// [math.MaxInt] as end. Iteration of seq stops as soon as the last
+// element in range has been yielded. Slice panics if step is not
+// positive.
+func Slice[T any](seq iter.Seq[T], start, end, step int) iter.Seq[T] {
+	if step <= 0 {
+		panic("xiter: non-positive slice step")
+	}
+	start = max(start, 0)
+
+	return func(yield func(T) bool) {
+		if end <= start {
+			return
+		}
+
+		last := start + (end-1-start)/step*step
+		var i int
+		seq(func(v T) bool {
+			if i >= start && (i-start)%step == 0 {
+				if !yield(v) || i == last {
+					return false
+				}
+			}
+			i++
+			return true
+		})
+	}
+}
+
 // Concat creates a new Seq that yields the values of each of the
 // provided Seqs in turn.
 func Concat[T any](seqs ...iter.Seq[T]) iter.Seq[T] {
@@ -97,6 +250,74 @@ func Flatten[T any](seq iter.Seq[iter.Seq[T]]) iter.Seq[T] {
 	}
 }
 
+// FlatMap returns a Seq that yields all of the elements of the Seq
+// returned by f for each element of seq in turn. It is equivalent to
+// Flatten(Map(seq, f)) but with less overhead.
+func FlatMap[T, U any](seq iter.Seq[T], f func(T) iter.Seq[U]) iter.Seq[U] {
+	return func(yield func(U) bool) {
+		for v := range seq {
+			for u := range f(v) {
+				if !yield(u) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// FlattenSlices yields all of the elements of each slice yielded from
+// seq in turn.
+func FlattenSlices[T any](seq iter.Seq[[]T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for s := range seq {
+			if !yieldAll(s, yield) {
+				return
+			}
+		}
+	}
+}
+
+// Expand returns a Seq that recursively expands the elements of seq
+// depth-first, yielding each element followed by the expansion of
+// the elements returned by children for it. The elements of seq are
+// at depth 0 and children is not called for elements at maxDepth. If
+// maxDepth is negative, there is no limit. To protect against cycles,
+// an element that is returned by children for one of its own
+// descendants is skipped, cutting the cycle. Elements that are
+// reachable along several different paths, such as in a DAG, are
+// yielded once for each of them.
+func Expand[T comparable](seq iter.Seq[T], children func(T) iter.Seq[T], maxDepth int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		// path holds the ancestors of the elements currently being
+		// expanded.
+		path := make(map[T]struct{})
+
+		var expand func(iter.Seq[T], int) bool
+		expand = func(seq iter.Seq[T], depth int) bool {
+			for v := range seq {
+				if _, ok := path[v]; ok {
+					continue
+				}
+				if !yield(v) {
+					return false
+				}
+				if maxDepth >= 0 && depth >= maxDepth {
+					continue
+				}
+
+				path[v] = struct{}{}
+				ok := expand(children(v), depth+1)
+				delete(path, v)
+				if !ok {
+					return false
+				}
+			}
+			return true
+		}
+		expand(seq, 0)
+	}
+}
+
 // Zipped holds values from an iteration of a Seq returned by [Zip].
 type Zipped[T1, T2 any] struct {
 	V1  T1
@@ -172,6 +393,83 @@ func MergeFunc[T any](seq1, seq2 iter.Seq[T], compare func(T, T) int) iter.Seq[T
 	}
 }
 
+// Intersperse returns a Seq that yields the elements of seq with sep
+// yielded between each pair of them.
+func Intersperse[T any](seq iter.Seq[T], sep T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		first := true
+		seq(func(v T) bool {
+			if !first && !yield(sep) {
+				return false
+			}
+			first = false
+			return yield(v)
+		})
+	}
+}
+
+// Interleave returns a Seq that yields one element from each of seqs
+// in turn, repeatedly. When one of seqs is exhausted, it is dropped
+// and the remaining ones continue to alternate until all of them are
+// exhausted.
+func Interleave[T any](seqs ...iter.Seq[T]) iter.Seq[T] {
+	weights := make([]int, len(seqs))
+	for i := range weights {
+		weights[i] = 1
+	}
+	return RoundRobin(weights, seqs...)
+}
+
+// RoundRobin is like [Interleave] but yields up to weights[i]
+// elements from seqs[i] on its turn instead of just one, so that each
+// of seqs gets a share of the output proportional to its weight for
+// as long as it has elements. It panics if the number of weights does
+// not match the number of seqs or if any weight is not positive.
+func RoundRobin[T any](weights []int, seqs ...iter.Seq[T]) iter.Seq[T] {
+	if len(weights) != len(seqs) {
+		panic("xiter: mismatched weights and seqs")
+	}
+	if slices.ContainsFunc(weights, func(w int) bool { return w <= 0 }) {
+		panic("xiter: non-positive weight")
+	}
+
+	return func(yield func(T) bool) {
+		type source struct {
+			next   func() (T, bool)
+			weight int
+		}
+
+		sources := make([]source, 0, len(seqs))
+		for i, seq := range seqs {
+			next, stop := iter.Pull(seq)
+			defer stop()
+			sources = append(sources, source{next: next, weight: weights[i]})
+		}
+
+		for len(sources) > 0 {
+			active := sources[:0]
+			for _, s := range sources {
+				ok := true
+				for range s.weight {
+					var v T
+					v, ok = s.next()
+					if !ok {
+						break
+					}
+					if !yield(v) {
+						return
+					}
+				}
+				if ok {
+					active = append(active, s)
+				}
+			}
+			clear(sources[len(active):])
+			sources = active
+		}
+	}
+}
+
 // Windows returns a slice over successive overlapping portions of
 // size n of the values yielded by seq. In other words,
 //
@@ -185,9 +483,19 @@ func MergeFunc[T any](seq1, seq2 iter.Seq[T], compare func(T, T) int) iter.Seq[T
 //
 // and so on. The slice yielded is reused from one iteration to the
 // next, so it should not be held onto after each iteration has ended.
-// [Map] and [slices.Clone] may come in handy for dealing with
-// situations where this is necessary.
+// For a version that yields a new slice each time, see [WindowsCopy].
 func Windows[T any](seq iter.Seq[T], n int) iter.Seq[[]T] {
+	return windows(seq, n, false)
+}
+
+// WindowsCopy is like [Windows] but yields a newly allocated slice
+// for every window, allowing the windows to be held onto after each
+// iteration has ended.
+func WindowsCopy[T any](seq iter.Seq[T], n int) iter.Seq[[]T] {
+	return windows(seq, n, true)
+}
+
+func windows[T any](seq iter.Seq[T], n int, clone bool) iter.Seq[[]T] {
 	return func(yield func([]T) bool) {
 		win := make([]T, 0, n)
 
@@ -198,16 +506,202 @@ func Windows[T any](seq iter.Seq[T], n int) iter.Seq[[]T] {
 			}
 			if len(win) < n {
 				win = append(win, v)
-				return yield(win)
+				return yield(own(win, clone))
 			}
 
 			copy(win, win[1:])
 			win[len(win)-1] = v
-			return yield(win)
+			return yield(own(win, clone))
 		})
 		if len(win) < n {
-			yield(win)
+			yield(own(win, clone))
+		}
+	}
+}
+
+// Partial determines what [WindowsStep] does with a window at the end
+// of its input that has fewer elements than the window size.
+type Partial int
+
+const (
+	// PartialDrop discards partial windows.
+	PartialDrop Partial = iota
+
+	// PartialYield yields partial windows as they are, shorter than
+	// the others.
+	PartialYield
+
+	// PartialPad yields partial windows padded out to the full size
+	// with a given value.
+	PartialPad
+)
+
+// WindowsStep is like [Windows] but the start of each window is step
+// elements after the start of the previous one, rather than one. If
+// step is greater than size, the elements between windows are
+// skipped. For example,
+//
+//	WindowsStep(Generate(0, 1), 3, 2, PartialDrop, 0)
+//
+// will yield
+//
+//	[0, 1, 2]
+//	[2, 3, 4]
+//	[4, 5, 6]
+//
+// and so on. If seq ends with elements that are not in any full
+// window, a single partial window is handled according to partial,
+// starting where the next full window would have and containing the
+// rest of the elements of seq. pad is only used with [PartialPad].
+//
+// Like with Windows, the slice is reused between iterations. For a
+// version that yields a new slice each time, see [WindowsStepCopy].
+// WindowsStep panics if size or step is not positive.
+func WindowsStep[T any](seq iter.Seq[T], size, step int, partial Partial, pad T) iter.Seq[[]T] {
+	return windowsStep(seq, size, step, partial, pad, false)
+}
+
+// WindowsStepCopy is like [WindowsStep] but yields a newly allocated
+// slice for every window.
+func WindowsStepCopy[T any](seq iter.Seq[T], size, step int, partial Partial, pad T) iter.Seq[[]T] {
+	return windowsStep(seq, size, step, partial, pad, true)
+}
+
+func windowsStep[T any](seq iter.Seq[T], size, step int, partial Partial, pad T, clone bool) iter.Seq[[]T] {
+	if size <= 0 || step <= 0 {
+		panic("xiter: non-positive window size or step")
+	}
+
+	return func(yield func([]T) bool) {
+		win := make([]T, 0, size)
+
+		// skip is the number of elements left in the gap between
+		// windows and fresh is true if win contains elements that have
+		// not been yielded yet.
+		var skip int
+		var fresh bool
+		cont := true
+		seq(func(v T) bool {
+			if skip > 0 {
+				skip--
+				return true
+			}
+
+			win = append(win, v)
+			fresh = true
+			if len(win) < size {
+				return true
+			}
+
+			cont = yield(own(win, clone))
+			fresh = false
+			if step < size {
+				n := copy(win, win[step:])
+				clear(win[n:])
+				win = win[:n]
+			} else {
+				clear(win)
+				win = win[:0]
+				skip = step - size
+			}
+			return cont
+		})
+		if !cont || !fresh {
+			return
+		}
+
+		switch partial {
+		case PartialYield:
+			yield(own(win, clone))
+		case PartialPad:
+			for len(win) < size {
+				win = append(win, pad)
+			}
+			yield(own(win, clone))
+		}
+	}
+}
+
+// NGrams is like [Windows] but only ever yields complete windows of
+// n elements. If seq yields fewer than n elements or n is not
+// positive, nothing is yielded. Like with Windows, the slice is
+// reused between iterations.
+func NGrams[T any](seq iter.Seq[T], n int) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		if n <= 0 {
+			return
+		}
+
+		gram := make([]T, 0, n)
+		seq(func(v T) bool {
+			if len(gram) < n {
+				gram = append(gram, v)
+				return len(gram) < n || yield(gram)
+			}
+
+			copy(gram, gram[1:])
+			gram[n-1] = v
+			return yield(gram)
+		})
+	}
+}
+
+// PaddedNGrams is like [NGrams] but first pads both ends of seq with
+// n-1 copies of pad so that the elements at the edges of seq appear
+// in as many grams as the rest. For example,
+//
+//	PaddedNGrams(Of("a", "b"), 3, "")
+//
+// will yield
+//
+//	["", "", "a"]
+//	["", "a", "b"]
+//	["a", "b", ""]
+//	["b", "", ""]
+func PaddedNGrams[T any](seq iter.Seq[T], n int, pad T) iter.Seq[[]T] {
+	if n <= 1 {
+		return NGrams(seq, n)
+	}
+
+	pads := slices.Values(slices.Repeat([]T{pad}, n-1))
+	return NGrams(Concat(pads, seq, pads), n)
+}
+
+// RollingHashes returns a Seq over hashes of each window of k
+// elements of seq, as would be yielded by [NGrams]. Each element is
+// hashed using the provided function, and the hashes of the elements
+// in a window are combined into a polynomial rolling hash that is
+// updated in constant time as the window slides. This makes it
+// useful for fingerprinting long sequences, such as for MinHash
+// similarity estimation.
+func RollingHashes[T any](seq iter.Seq[T], k int, hash func(T) uint64) iter.Seq[uint64] {
+	// FNV-1's 64-bit prime.
+	const base = 1099511628211
+
+	return func(yield func(uint64) bool) {
+		if k <= 0 {
+			return
 		}
+
+		// pow is the multiplier of the oldest element in the window.
+		pow := uint64(1)
+		for range k - 1 {
+			pow *= base
+		}
+
+		window := make([]uint64, k)
+		var h uint64
+		var n int
+		seq(func(v T) bool {
+			x := hash(v)
+			if n >= k {
+				h -= window[n%k] * pow
+			}
+			h = h*base + x
+			window[n%k] = x
+			n++
+			return n < k || yield(h)
+		})
 	}
 }
 
@@ -222,8 +716,19 @@ func Windows[T any](seq iter.Seq[T], n int) iter.Seq[[]T] {
 //	[3, 4, 5]
 //	[6, 7, 8]
 //
-// Like with Windows, the slice is reused between iterations.
+// Like with Windows, the slice is reused between iterations. For a
+// version that yields a new slice each time, see [ChunksCopy].
 func Chunks[T any](seq iter.Seq[T], n int) iter.Seq[[]T] {
+	return chunks(seq, n, false)
+}
+
+// ChunksCopy is like [Chunks] but yields a newly allocated slice for
+// every chunk.
+func ChunksCopy[T any](seq iter.Seq[T], n int) iter.Seq[[]T] {
+	return chunks(seq, n, true)
+}
+
+func chunks[T any](seq iter.Seq[T], n int, clone bool) iter.Seq[[]T] {
 	return func(yield func([]T) bool) {
 		win := make([]T, 0, n)
 
@@ -239,16 +744,88 @@ func Chunks[T any](seq iter.Seq[T], n int) iter.Seq[[]T] {
 			}
 			if len(win) < n {
 				win = append(win, v)
-				return yield(win)
+				return yield(own(win, clone))
 			}
 
 			// This should only be reachable if n is 0, so just yield a
 			// bunch of empty slices because why not?
-			return yield(win)
+			return yield(own(win, clone))
 		})
 		if len(win) < n {
-			yield(win)
+			yield(own(win, clone))
+		}
+	}
+}
+
+// Oversize determines what [BatchOversize] does with an element that
+// is too heavy to fit into any batch.
+type Oversize int
+
+const (
+	// OversizeAlone yields the element in a batch by itself.
+	OversizeAlone Oversize = iota
+
+	// OversizeDrop discards the element.
+	OversizeDrop
+)
+
+// Batch groups the elements of seq into batches, starting a new batch
+// whenever adding the next element to the current one would make it
+// contain more than maxCount elements or make the sum of the weights
+// of its elements, as determined by weight, greater than maxWeight.
+// This is useful for operations that have limits on both the number
+// of items and their total size, such as bulk inserts where weight
+// returns the encoded size of each row. An element that is heavier
+// than maxWeight on its own is yielded in a batch by itself. To
+// handle such elements differently, use [BatchOversize].
+//
+// If maxCount is not positive, batches are only limited by weight,
+// and if weight is nil, they are only limited by count. Unlike with
+// Chunks, each batch is a newly allocated slice.
+func Batch[T any, W Real](seq iter.Seq[T], maxCount int, maxWeight W, weight func(T) W) iter.Seq[[]T] {
+	return BatchOversize(seq, maxCount, maxWeight, weight, OversizeAlone)
+}
+
+// BatchOversize is like [Batch] but uses oversize to determine what to
+// do with elements that are heavier than maxWeight.
+func BatchOversize[T any, W Real](seq iter.Seq[T], maxCount int, maxWeight W, weight func(T) W, oversize Oversize) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		var batch []T
+		var total, zero W
+		flush := func() bool {
+			if len(batch) == 0 {
+				return true
+			}
+			b := batch
+			batch, total = nil, zero
+			return yield(b)
+		}
+
+		for v := range seq {
+			var w W
+			if weight != nil {
+				w = weight(v)
+				if w > maxWeight {
+					if oversize == OversizeDrop {
+						continue
+					}
+					if !flush() || !yield([]T{v}) {
+						return
+					}
+					continue
+				}
+				if total+w > maxWeight && !flush() {
+					return
+				}
+			}
+
+			batch = append(batch, v)
+			total += w
+			if maxCount > 0 && len(batch) >= maxCount && !flush() {
+				return
+			}
 		}
+		flush()
 	}
 }
 
@@ -256,8 +833,19 @@ func Chunks[T any](seq iter.Seq[T], n int) iter.Seq[[]T] {
 // by calling chunker on successive elements. When the return value of
 // the function changes from the previous call, a new chunk is started.
 //
-// Like with Chunks, the slice is reused between iterations.
+// Like with Chunks, the slice is reused between iterations. For a
+// version that yields a new slice each time, see [ChunksFuncCopy].
 func ChunksFunc[T any, C comparable](seq iter.Seq[T], chunker func(T) C) iter.Seq[[]T] {
+	return chunksFunc(seq, chunker, false)
+}
+
+// ChunksFuncCopy is like [ChunksFunc] but yields a newly allocated
+// slice for every chunk.
+func ChunksFuncCopy[T any, C comparable](seq iter.Seq[T], chunker func(T) C) iter.Seq[[]T] {
+	return chunksFunc(seq, chunker, true)
+}
+
+func chunksFunc[T any, C comparable](seq iter.Seq[T], chunker func(T) C, clone bool) iter.Seq[[]T] {
 	return func(yield func([]T) bool) {
 		next, stop := iter.Pull(seq)
 		defer stop()
@@ -273,7 +861,7 @@ func ChunksFunc[T any, C comparable](seq iter.Seq[T], chunker func(T) C) iter.Se
 			cur, ok := next()
 			if !ok {
 				if len(win) != 0 {
-					yield(win)
+					yield(own(win, clone))
 				}
 				return
 			}
@@ -284,7 +872,7 @@ func ChunksFunc[T any, C comparable](seq iter.Seq[T], chunker func(T) C) iter.Se
 				continue
 			}
 
-			if !yield(slices.Clip(win)) {
+			if !yield(own(slices.Clip(win), clone)) {
 				return
 			}
 			clear(win)
@@ -296,6 +884,61 @@ func ChunksFunc[T any, C comparable](seq iter.Seq[T], chunker func(T) C) iter.Se
 	}
 }
 
+// GroupBy returns a Seq2 that groups consecutive elements of seq for
+// which key returns the same value, in the manner of Python's
+// itertools.groupby. Each group is yielded as its key along with a
+// Seq over its elements that streams them directly from seq, so no
+// group is ever collected into memory. As with [ChunksFunc], elements
+// are only grouped with their neighbors, so seq usually needs to be
+// sorted by key first.
+//
+// The Seq for a group is only valid until the returned Seq2 moves on
+// to the next group. Any elements of the group that have not been
+// consumed by then are skipped, and the group's Seq yields nothing
+// more after that. A group's Seq also only yields each element once,
+// so iterating over it again continues from where it left off.
+func GroupBy[T any, K comparable](seq iter.Seq[T], key func(T) K) iter.Seq2[K, iter.Seq[T]] {
+	return func(yield func(K, iter.Seq[T]) bool) {
+		next, stop := iter.Pull(seq)
+		defer stop()
+
+		v, ok := next()
+		var vk K
+		if ok {
+			vk = key(v)
+		}
+
+		for ok {
+			k := vk
+			done := false
+			advance := func() T {
+				cur := v
+				v, ok = next()
+				if ok {
+					vk = key(v)
+				}
+				done = !ok || vk != k
+				return cur
+			}
+
+			group := func(yield func(T) bool) {
+				for !done {
+					if !yield(advance()) {
+						return
+					}
+				}
+			}
+			if !yield(k, group) {
+				return
+			}
+
+			for !done {
+				advance()
+			}
+		}
+	}
+}
+
 // Split returns a SplitSeq which yields the values of seq for which
 // f(value) is true to its first yield function and the rest to its
 // second.
@@ -341,6 +984,57 @@ func Cache[T any](seq iter.Seq[T]) iter.Seq[T] {
 	}
 }
 
+// Scan returns a Seq that performs a [Reduce] over seq, yielding
+// each intermediate result of reducer as it is computed. The final
+// value yielded is the one that Reduce would have returned. initial
+// itself is not yielded, so the returned Seq yields exactly as many
+// values as seq does.
+func Scan[T, R any](seq iter.Seq[T], initial R, reducer func(R, T) R) iter.Seq[R] {
+	return func(yield func(R) bool) {
+		acc := initial
+		seq(func(v T) bool {
+			acc = reducer(acc, v)
+			return yield(acc)
+		})
+	}
+}
+
+// Scan1 is like [Scan] but performs a [Fold] instead of a Reduce,
+// yielding the first value of seq as is and using it in place of an
+// initial value.
+func Scan1[T any](seq iter.Seq[T], reducer func(T, T) T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		var acc T
+		first := true
+		seq(func(v T) bool {
+			if first {
+				acc, first = v, false
+			} else {
+				acc = reducer(acc, v)
+			}
+			return yield(acc)
+		})
+	}
+}
+
+// RunningSum returns a Seq that yields the sum of the values of seq
+// so far after each one, such as prefix sums or a running balance.
+func RunningSum[T Addable](seq iter.Seq[T]) iter.Seq[T] {
+	return Scan1(seq, func(total, v T) T { return total + v })
+}
+
+// RunningMin returns a Seq that yields the minimum of the values of
+// seq so far after each one.
+func RunningMin[T cmp.Ordered](seq iter.Seq[T]) iter.Seq[T] {
+	return Scan1(seq, func(v1, v2 T) T { return min(v1, v2) })
+}
+
+// RunningMax returns a Seq that yields the maximum of the values of
+// seq so far after each one.
+func RunningMax[T cmp.Ordered](seq iter.Seq[T]) iter.Seq[T] {
+	return Scan1(seq, func(v1, v2 T) T { return max(v1, v2) })
+}
+
 // Enumerate returns a Seq2 that counts the number of iterations of
 // seq as it yields elements from it, starting at 0.
 func Enumerate[T any](seq iter.Seq[T]) iter.Seq2[int, T] {
@@ -460,3 +1154,223 @@ func SortedFunc[T any](seq iter.Seq[T], compare func(T, T) int) iter.Seq[T] {
 		}
 	}
 }
+
+// prefixTable returns the Knuth-Morris-Pratt failure function of
+// pattern. The value at index i is the length of the longest proper
+// prefix of pattern[:i+1] that is also a suffix of it.
+func prefixTable[T comparable](pattern []T) []int {
+	table := make([]int, len(pattern))
+	var k int
+	for i := 1; i < len(pattern); i++ {
+		for k > 0 && pattern[i] != pattern[k] {
+			k = table[k-1]
+		}
+		if pattern[i] == pattern[k] {
+			k++
+		}
+		table[i] = k
+	}
+	return table
+}
+
+// SplitOnSubseq returns a Seq that yields the portions of seq that
+// are separated by the elements of sep appearing consecutively. It is
+// a generalization of [StringSplit] to arbitrary sequences and, like
+// it, always yields at least one, possibly empty, slice. If sep is
+// empty, each element is yielded in a slice by itself. Matching uses
+// the Knuth-Morris-Pratt algorithm, so beyond the portion currently
+// being collected only O(len(sep)) memory is used, making it safe
+// to use with unbounded sequences.
+//
+// Like with [Chunks], the slice is reused between iterations.
+func SplitOnSubseq[T comparable](seq iter.Seq[T], sep []T) iter.Seq[[]T] {
+	if len(sep) == 0 {
+		return Chunks(seq, 1)
+	}
+
+	table := prefixTable(sep)
+	return func(yield func([]T) bool) {
+		var cur []T
+		var j int
+		for v := range seq {
+			cur = append(cur, v)
+
+			for j > 0 && v != sep[j] {
+				j = table[j-1]
+			}
+			if v != sep[j] {
+				continue
+			}
+			j++
+			if j < len(sep) {
+				continue
+			}
+
+			if !yield(cur[:len(cur)-len(sep)]) {
+				return
+			}
+			clear(cur)
+			cur, j = cur[:0], 0
+		}
+		yield(cur)
+	}
+}
+
+// SplitWhen returns a Seq that yields groups of consecutive elements
+// of seq, starting a new group between each pair of neighboring
+// elements for which split returns true. split is called with the
+// previous element and the current one, so, for example, a log of
+// events can be split into sessions wherever the gap between two
+// events is too long. If seq is empty, nothing is yielded. Unlike
+// with [ChunksFunc], each group is a newly allocated slice.
+func SplitWhen[T any](seq iter.Seq[T], split func(prev, cur T) bool) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		var group []T
+		for v := range seq {
+			if len(group) > 0 && split(group[len(group)-1], v) {
+				if !yield(group) {
+					return
+				}
+				group = nil
+			}
+			group = append(group, v)
+		}
+		if len(group) > 0 {
+			yield(group)
+		}
+	}
+}
+
+// SplitOn returns a Seq that yields the groups of elements of seq that
+// are separated by elements for which isSep returns true. The
+// separators themselves are not yielded. Like [StringSplit], it
+// always yields at least one group and yields empty groups between
+// adjacent separators and at the ends of seq if it begins or ends
+// with a separator. Each group is a newly allocated slice.
+func SplitOn[T any](seq iter.Seq[T], isSep func(T) bool) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		group := []T{}
+		for v := range seq {
+			if !isSep(v) {
+				group = append(group, v)
+				continue
+			}
+
+			if !yield(group) {
+				return
+			}
+			group = []T{}
+		}
+		yield(group)
+	}
+}
+
+// SplitAt returns the first n elements of seq in a slice along with
+// a Seq that yields the rest of them. The head is collected
+// immediately, but the tail is lazy and continues from where the head
+// left off without restarting seq, so it works with sequences that
+// can only be iterated once. If seq has n or fewer elements, the
+// tail yields nothing. If n is not positive, the head is empty and
+// the tail is seq itself.
+//
+// The tail may only be iterated once. Unless seq ran out while the
+// head was being collected, seq remains suspended until then, so the
+// tail should always be iterated, even if iteration is stopped
+// immediately, in order to release it.
+func SplitAt[T any](seq iter.Seq[T], n int) ([]T, iter.Seq[T]) {
+	if n <= 0 {
+		return nil, seq
+	}
+
+	next, stop := iter.Pull(seq)
+	var head []T
+	for len(head) < n {
+		v, ok := next()
+		if !ok {
+			stop()
+			return head, func(func(T) bool) {}
+		}
+		head = append(head, v)
+	}
+
+	return head, func(yield func(T) bool) {
+		defer stop()
+		for {
+			v, ok := next()
+			if !ok || !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// ReplaceSubseq returns a Seq that yields the elements of seq but
+// with every non-overlapping run of elements equal to old replaced
+// with the elements of new. It is a generalization of
+// [strings.ReplaceAll] to arbitrary sequences. If old is empty, new
+// is inserted at the start and after every element. Like
+// [SplitOnSubseq], it uses only O(len(old)) memory.
+func ReplaceSubseq[T comparable](seq iter.Seq[T], old, new []T) iter.Seq[T] {
+	if len(old) == 0 {
+		return func(yield func(T) bool) {
+			if !yieldAll(new, yield) {
+				return
+			}
+			for v := range seq {
+				if !yield(v) || !yieldAll(new, yield) {
+					return
+				}
+			}
+		}
+	}
+
+	table := prefixTable(old)
+	return func(yield func(T) bool) {
+		var j int
+		for v := range seq {
+			for j > 0 && v != old[j] {
+				// The elements that were matched so far are equal to
+				// old[:j], so the ones that can no longer be part of a
+				// match are equal to the start of old.
+				k := table[j-1]
+				if !yieldAll(old[:j-k], yield) {
+					return
+				}
+				j = k
+			}
+
+			if v != old[j] {
+				if !yield(v) {
+					return
+				}
+				continue
+			}
+			j++
+			if j == len(old) {
+				if !yieldAll(new, yield) {
+					return
+				}
+				j = 0
+			}
+		}
+		yieldAll(old[:j], yield)
+	}
+}
+
+// yieldAll yields each element of s, returning false if yield does.
+func yieldAll[T any](s []T, yield func(T) bool) bool {
+	for _, v := range s {
+		if !yield(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// own returns s if clone is false or a copy of it if clone is true.
+func own[S ~[]T, T any](s S, clone bool) S {
+	if clone {
+		return slices.Clone(s)
+	}
+	return s
+}
@@ -3,8 +3,12 @@ package xiter
 import (
 	"bytes"
 	"cmp"
+	"io"
 	"iter"
+	"math"
 	"slices"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -35,6 +39,52 @@ func TestSkip(t *testing.T) {
 	}
 }
 
+func TestDropWhile(t *testing.T) {
+	s := slices.Collect(DropWhile(slices.Values([]int{1, 2, 5, 1, 6}), func(v int) bool { return v < 3 }))
+	if !slices.Equal(s, []int{5, 1, 6}) {
+		t.Fatal(s)
+	}
+}
+
+func TestDropLast(t *testing.T) {
+	seq := slices.Values([]int{1, 2, 3, 4, 5})
+	tests := []struct {
+		n        int
+		expected []int
+	}{
+		{-1, []int{1, 2, 3, 4, 5}},
+		{0, []int{1, 2, 3, 4, 5}},
+		{2, []int{1, 2, 3}},
+		{5, nil},
+		{7, nil},
+		{1 << 62, nil},
+	}
+	for _, test := range tests {
+		s := slices.Collect(DropLast(seq, test.n))
+		if !slices.Equal(s, test.expected) {
+			t.Fatal(test.n, s)
+		}
+	}
+}
+
+func TestUntilEOF(t *testing.T) {
+	var s []byte
+	for c, err := range UntilEOF(ReadBytes(strings.NewReader("test"))) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		s = append(s, c)
+	}
+	if string(s) != "test" {
+		t.Fatal(s)
+	}
+
+	errs := slices.Collect(V2(UntilEOF(FromPair(Of(P(1, error(nil)), P(0, io.ErrUnexpectedEOF), P(0, io.EOF))))))
+	if !slices.Equal(errs, []error{nil, io.ErrUnexpectedEOF}) {
+		t.Fatal(errs)
+	}
+}
+
 func TestLimit(t *testing.T) {
 	s := slices.Collect(Limit(Generate(
 		0, 2),
@@ -43,6 +93,85 @@ func TestLimit(t *testing.T) {
 	if [3]int(s) != [...]int{0, 2, 4} {
 		t.Fatal(s)
 	}
+
+	var called bool
+	s = slices.Collect(Limit(func(yield func(int) bool) { called = true; yield(1) }, 0))
+	if len(s) != 0 || called {
+		t.Fatal(s, called)
+	}
+
+	seq := Limit(slices.Values([]int{0, 1, 2}), 2)
+	s = slices.Collect(seq)
+	s = slices.Collect(seq)
+	if !slices.Equal(s, []int{0, 1}) {
+		t.Fatal(s)
+	}
+}
+
+func TestTakeWhile(t *testing.T) {
+	s := slices.Collect(TakeWhile(Generate(0, 1), func(v int) bool { return v < 3 }))
+	if !slices.Equal(s, []int{0, 1, 2}) {
+		t.Fatal(s)
+	}
+}
+
+func TestTakeLast(t *testing.T) {
+	seq := slices.Values([]int{1, 2, 3, 4, 5})
+	tests := []struct {
+		n        int
+		expected []int
+	}{
+		{-1, nil},
+		{0, nil},
+		{2, []int{4, 5}},
+		{3, []int{3, 4, 5}},
+		{7, []int{1, 2, 3, 4, 5}},
+		{1 << 62, []int{1, 2, 3, 4, 5}},
+	}
+	for _, test := range tests {
+		s := slices.Collect(TakeLast(seq, test.n))
+		if !slices.Equal(s, test.expected) {
+			t.Fatal(test.n, s)
+		}
+	}
+}
+
+func TestSlice(t *testing.T) {
+	tests := []struct {
+		start, end, step int
+		expected         []int
+	}{
+		{0, 5, 1, []int{0, 1, 2, 3, 4}},
+		{2, 9, 3, []int{2, 5, 8}},
+		{-3, 3, 2, []int{0, 2}},
+		{4, 4, 1, nil},
+		{5, 2, 1, nil},
+	}
+	for _, test := range tests {
+		s := slices.Collect(Slice(Generate(0, 1), test.start, test.end, test.step))
+		if !slices.Equal(s, test.expected) {
+			t.Fatal(test.start, test.end, test.step, s)
+		}
+	}
+
+	s := slices.Collect(Slice(slices.Values([]int{0, 1, 2, 3}), 1, math.MaxInt, 2))
+	if !slices.Equal(s, []int{1, 3}) {
+		t.Fatal(s)
+	}
+
+	var pulled int
+	counted := func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			pulled++
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	s = slices.Collect(Slice(counted, 0, 10, 5))
+	if !slices.Equal(s, []int{0, 5}) || pulled != 6 {
+		t.Fatal(s, pulled)
+	}
 }
 
 func TestConcat(t *testing.T) {
@@ -95,6 +224,91 @@ func TestIsSorted(t *testing.T) {
 	}
 }
 
+func TestFlatMap(t *testing.T) {
+	s := slices.Collect(FlatMap(Of(1, 2, 3), func(v int) iter.Seq[int] { return Limit(Generate(v, 0), v) }))
+	if !slices.Equal(s, []int{1, 2, 2, 3, 3, 3}) {
+		t.Fatal(s)
+	}
+}
+
+func TestFlattenSlices(t *testing.T) {
+	s := slices.Collect(FlattenSlices(Of([]int{1, 2}, nil, []int{3})))
+	if !slices.Equal(s, []int{1, 2, 3}) {
+		t.Fatal(s)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	graph := map[string][]string{
+		"a": {"b", "c"},
+		"b": {"d", "a"},
+		"c": {"d"},
+		"d": {"e"},
+	}
+	children := func(v string) iter.Seq[string] { return slices.Values(graph[v]) }
+
+	s := slices.Collect(Expand(Of("a"), children, -1))
+	if !slices.Equal(s, []string{"a", "b", "d", "e", "c", "d", "e"}) {
+		t.Fatal(s)
+	}
+
+	s = slices.Collect(Expand(Of("a"), children, 1))
+	if !slices.Equal(s, []string{"a", "b", "c"}) {
+		t.Fatal(s)
+	}
+
+	s = slices.Collect(Expand(Of("a", "d"), children, 0))
+	if !slices.Equal(s, []string{"a", "d"}) {
+		t.Fatal(s)
+	}
+
+	s = slices.Collect(Expand(Of("a", "a"), children, 0))
+	if !slices.Equal(s, []string{"a", "a"}) {
+		t.Fatal(s)
+	}
+
+	graph = map[string][]string{
+		"x": {"y", "z"},
+		"y": {"z"},
+		"z": {"w"},
+	}
+	s = slices.Collect(Expand(Of("x"), children, 2))
+	if !slices.Equal(s, []string{"x", "y", "z", "z", "w"}) {
+		t.Fatal(s)
+	}
+}
+
+func TestIntersperse(t *testing.T) {
+	s := slices.Collect(Intersperse(slices.Values([]string{"a", "b", "c"}), ","))
+	if !slices.Equal(s, []string{"a", ",", "b", ",", "c"}) {
+		t.Fatal(s)
+	}
+
+	s = slices.Collect(Intersperse(Of("a"), ","))
+	if !slices.Equal(s, []string{"a"}) {
+		t.Fatal(s)
+	}
+}
+
+func TestInterleave(t *testing.T) {
+	s := slices.Collect(Interleave(Of(1, 2, 3), Of[int](), Of(10), Of(20, 21)))
+	if !slices.Equal(s, []int{1, 10, 20, 2, 21, 3}) {
+		t.Fatal(s)
+	}
+
+	s = slices.Collect(Limit(Interleave(Generate(0, 2), Generate(1, 2)), 5))
+	if !slices.Equal(s, []int{0, 1, 2, 3, 4}) {
+		t.Fatal(s)
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	s := slices.Collect(RoundRobin([]int{2, 1}, Of(1, 2, 3, 4, 5), Of(10, 11)))
+	if !slices.Equal(s, []int{1, 2, 10, 3, 4, 11, 5}) {
+		t.Fatal(s)
+	}
+}
+
 func TestMerge(t *testing.T) {
 	s1 := slices.Values([]int{2, 3, 5})
 	s2 := slices.Values([]int{1, 2, 3, 4, 5})
@@ -139,6 +353,51 @@ func FuzzMergeSort(f *testing.F) {
 	})
 }
 
+func TestWindowsStep(t *testing.T) {
+	tests := []struct {
+		n, size, step int
+		partial       Partial
+		expected      [][]int
+	}{
+		{7, 3, 2, PartialDrop, [][]int{{0, 1, 2}, {2, 3, 4}, {4, 5, 6}}},
+		{6, 3, 2, PartialDrop, [][]int{{0, 1, 2}, {2, 3, 4}}},
+		{6, 3, 2, PartialYield, [][]int{{0, 1, 2}, {2, 3, 4}, {4, 5}}},
+		{6, 3, 2, PartialPad, [][]int{{0, 1, 2}, {2, 3, 4}, {4, 5, -1}}},
+		{5, 3, 1, PartialYield, [][]int{{0, 1, 2}, {1, 2, 3}, {2, 3, 4}}},
+		{9, 2, 4, PartialYield, [][]int{{0, 1}, {4, 5}, {8}}},
+		{7, 2, 4, PartialYield, [][]int{{0, 1}, {4, 5}}},
+		{2, 3, 1, PartialPad, [][]int{{0, 1, -1}}},
+		{0, 3, 1, PartialYield, nil},
+	}
+	for _, test := range tests {
+		seq := Limit(Generate(0, 1), test.n)
+		s := slices.Collect(WindowsStepCopy(seq, test.size, test.step, test.partial, -1))
+		if !slices.EqualFunc(s, test.expected, slices.Equal) {
+			t.Errorf("%+v: %v", test, s)
+		}
+	}
+}
+
+func TestCopyVariants(t *testing.T) {
+	seq := slices.Values([]int{1, 2, 3, 4, 5})
+	if s := slices.Collect(WindowsCopy(seq, 4)); !slices.EqualFunc(s, [][]int{{1, 2, 3, 4}, {2, 3, 4, 5}}, slices.Equal) {
+		t.Fatal(s)
+	}
+	if s := slices.Collect(ChunksCopy(seq, 2)); !slices.EqualFunc(s, [][]int{{1, 2}, {3, 4}, {5}}, slices.Equal) {
+		t.Fatal(s)
+	}
+	if s := slices.Collect(ChunksFuncCopy(seq, func(v int) bool { return v < 3 })); !slices.EqualFunc(s, [][]int{{1, 2}, {3, 4, 5}}, slices.Equal) {
+		t.Fatal(s)
+	}
+
+	src := []int{1, 1, 2}
+	s := slices.Collect(SliceChunksFuncCopy(src, func(v int) int { return v }))
+	s[0][0] = 9
+	if src[0] != 1 || !slices.EqualFunc(s, [][]int{{9, 1}, {2}}, slices.Equal) {
+		t.Fatal(src, s)
+	}
+}
+
 func TestChunks(t *testing.T) {
 	s := slices.Collect(Map(Chunks(slices.Values([]int{1, 2, 3, 4, 5}),
 		2),
@@ -149,6 +408,31 @@ func TestChunks(t *testing.T) {
 	}
 }
 
+func TestBatch(t *testing.T) {
+	seq := slices.Values([]string{"a", "bb", "ccc", "dddddd", "e", "ff", "g", "h", "i"})
+	size := func(s string) int { return len(s) }
+
+	s := slices.Collect(Batch(seq, 3, 5, size))
+	if !slices.EqualFunc(s, [][]string{{"a", "bb"}, {"ccc"}, {"dddddd"}, {"e", "ff", "g"}, {"h", "i"}}, slices.Equal) {
+		t.Fatal(s)
+	}
+
+	s = slices.Collect(BatchOversize(seq, 3, 5, size, OversizeDrop))
+	if !slices.EqualFunc(s, [][]string{{"a", "bb"}, {"ccc", "e"}, {"ff", "g", "h"}, {"i"}}, slices.Equal) {
+		t.Fatal(s)
+	}
+
+	s = slices.Collect(Batch(seq, 4, 0, nil))
+	if !slices.EqualFunc(s, [][]string{{"a", "bb", "ccc", "dddddd"}, {"e", "ff", "g", "h"}, {"i"}}, slices.Equal) {
+		t.Fatal(s)
+	}
+
+	s = slices.Collect(Batch(seq, 0, 7, size))
+	if !slices.EqualFunc(s, [][]string{{"a", "bb", "ccc"}, {"dddddd", "e"}, {"ff", "g", "h", "i"}}, slices.Equal) {
+		t.Fatal(s)
+	}
+}
+
 func TestChunksFunc(t *testing.T) {
 	s := slices.Collect(Map(ChunksFunc(slices.Values([]int{0, 0, 0, 0, 1, 0, 1, 1, 0, 1}),
 		func(v int) bool { return v%2 == 0 }),
@@ -184,6 +468,69 @@ func TestCache(t *testing.T) {
 	}
 }
 
+func TestScan(t *testing.T) {
+	s := slices.Collect(Scan(slices.Values([]int{1, 2, 3}), "", func(acc string, v int) string {
+		return acc + strconv.Itoa(v)
+	}))
+	if !slices.Equal(s, []string{"1", "12", "123"}) {
+		t.Fatal(s)
+	}
+
+	s = slices.Collect(Scan(Of[int](), "x", func(acc string, v int) string { return acc }))
+	if len(s) != 0 {
+		t.Fatal(s)
+	}
+}
+
+func TestRunning(t *testing.T) {
+	seq := slices.Values([]int{3, 1, 4, 1, 5})
+	if s := slices.Collect(RunningSum(seq)); !slices.Equal(s, []int{3, 4, 8, 9, 14}) {
+		t.Fatal(s)
+	}
+	if s := slices.Collect(RunningMin(seq)); !slices.Equal(s, []int{3, 1, 1, 1, 1}) {
+		t.Fatal(s)
+	}
+	if s := slices.Collect(RunningMax(seq)); !slices.Equal(s, []int{3, 3, 4, 4, 5}) {
+		t.Fatal(s)
+	}
+	if s := slices.Collect(Limit(RunningSum(Generate(1, 1)), 4)); !slices.Equal(s, []int{1, 3, 6, 10}) {
+		t.Fatal(s)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	words := slices.Values([]string{"apple", "avocado", "banana", "blueberry", "cherry", "apricot"})
+	first := func(s string) byte { return s[0] }
+
+	var keys []byte
+	var groups [][]string
+	for k, group := range GroupBy(words, first) {
+		keys = append(keys, k)
+		groups = append(groups, slices.Collect(group))
+	}
+	if string(keys) != "abca" {
+		t.Fatalf("%q", keys)
+	}
+	if !slices.EqualFunc(groups, [][]string{{"apple", "avocado"}, {"banana", "blueberry"}, {"cherry"}, {"apricot"}}, slices.Equal) {
+		t.Fatal(groups)
+	}
+
+	var partial []string
+	var stale []iter.Seq[string]
+	for _, group := range GroupBy(words, first) {
+		partial = append(partial, slices.Collect(Limit(group, 1))...)
+		stale = append(stale, group)
+	}
+	if !slices.Equal(partial, []string{"apple", "banana", "cherry", "apricot"}) {
+		t.Fatal(partial)
+	}
+	for _, group := range stale {
+		if s := slices.Collect(group); len(s) != 0 {
+			t.Fatal(s)
+		}
+	}
+}
+
 func TestEnumerate(t *testing.T) {
 	s := slices.Collect(ToPair(Enumerate(Limit(Generate(0, 2), 3))))
 	if !slices.Equal(s, []Pair[int, int]{{0, 0}, {1, 2}, {2, 4}}) {
@@ -231,3 +578,136 @@ func TestSortedFunc(t *testing.T) {
 		t.Fatal(s)
 	}
 }
+
+func TestSplitOnSubseq(t *testing.T) {
+	seq := Bytes("GET / HTTP/1.1\r\nHost: a\r\n\r\nbody\r\n\r\n")
+	s := slices.Collect(Map(SplitOnSubseq(seq, []byte("\r\n\r\n")), func(b []byte) string { return string(b) }))
+	if !slices.Equal(s, []string{"GET / HTTP/1.1\r\nHost: a", "body", ""}) {
+		t.Fatalf("%q", s)
+	}
+}
+
+func TestSplitWhen(t *testing.T) {
+	times := slices.Values([]int{1, 2, 4, 15, 16, 40})
+	s := slices.Collect(SplitWhen(times, func(prev, cur int) bool { return cur-prev > 5 }))
+	if !slices.EqualFunc(s, [][]int{{1, 2, 4}, {15, 16}, {40}}, slices.Equal) {
+		t.Fatal(s)
+	}
+
+	s = slices.Collect(SplitWhen(Of[int](), func(prev, cur int) bool { return true }))
+	if len(s) != 0 {
+		t.Fatal(s)
+	}
+}
+
+func TestSplitOn(t *testing.T) {
+	isZero := func(v int) bool { return v == 0 }
+	s := slices.Collect(SplitOn(Of(1, 2, 0, 3, 0, 0, 4), isZero))
+	if !slices.EqualFunc(s, [][]int{{1, 2}, {3}, {}, {4}}, slices.Equal) {
+		t.Fatal(s)
+	}
+
+	s = slices.Collect(SplitOn(Of(0), isZero))
+	if !slices.EqualFunc(s, [][]int{{}, {}}, slices.Equal) {
+		t.Fatal(s)
+	}
+
+	s = slices.Collect(SplitOn(Of[int](), isZero))
+	if !slices.EqualFunc(s, [][]int{{}}, slices.Equal) {
+		t.Fatal(s)
+	}
+}
+
+func TestSplitAt(t *testing.T) {
+	var calls int
+	seq := func(yield func(int) bool) {
+		calls++
+		for i := range 5 {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+
+	head, tail := SplitAt(seq, 2)
+	if !slices.Equal(head, []int{0, 1}) {
+		t.Fatal(head)
+	}
+	if s := slices.Collect(tail); !slices.Equal(s, []int{2, 3, 4}) {
+		t.Fatal(s)
+	}
+	if calls != 1 {
+		t.Fatal(calls)
+	}
+
+	head, tail = SplitAt(seq, 7)
+	if !slices.Equal(head, []int{0, 1, 2, 3, 4}) {
+		t.Fatal(head)
+	}
+	if s := slices.Collect(tail); len(s) != 0 {
+		t.Fatal(s)
+	}
+
+	head, tail = SplitAt(seq, 1<<62)
+	if !slices.Equal(head, []int{0, 1, 2, 3, 4}) {
+		t.Fatal(head)
+	}
+	if s := slices.Collect(tail); len(s) != 0 {
+		t.Fatal(s)
+	}
+
+	head, tail = SplitAt(seq, 0)
+	if len(head) != 0 {
+		t.Fatal(head)
+	}
+	if s := slices.Collect(tail); !slices.Equal(s, []int{0, 1, 2, 3, 4}) {
+		t.Fatal(s)
+	}
+}
+
+func TestReplaceSubseq(t *testing.T) {
+	tests := []struct{ s, old, new string }{
+		{"aababcabcd", "abc", "X"},
+		{"aaaa", "aa", "b"},
+		{"abab", "aba", ""},
+		{"abc", "", "-"},
+	}
+	for _, test := range tests {
+		s := string(slices.Collect(ReplaceSubseq(Bytes(test.s), []byte(test.old), []byte(test.new))))
+		if check := strings.ReplaceAll(test.s, test.old, test.new); s != check {
+			t.Errorf("%q, %q, %q: %q != %q", test.s, test.old, test.new, s, check)
+		}
+	}
+}
+
+func TestNGrams(t *testing.T) {
+	s := slices.Collect(Map(NGrams(Of(1, 2, 3, 4), 3), slices.Clone))
+	if !slices.EqualFunc(s, [][]int{{1, 2, 3}, {2, 3, 4}}, slices.Equal) {
+		t.Fatal(s)
+	}
+
+	s = slices.Collect(NGrams(Of(1, 2), 3))
+	if len(s) != 0 {
+		t.Fatal(s)
+	}
+
+	p := slices.Collect(Map(PaddedNGrams(Of("a", "b"), 3, ""), slices.Clone))
+	if !slices.EqualFunc(p, [][]string{{"", "", "a"}, {"", "a", "b"}, {"a", "b", ""}, {"b", "", ""}}, slices.Equal) {
+		t.Fatal(p)
+	}
+}
+
+func TestRollingHashes(t *testing.T) {
+	hash := func(v int) uint64 { return uint64(v) }
+	s := slices.Collect(RollingHashes(Of(1, 2, 3, 1, 2, 3), 3, hash))
+	if len(s) != 4 || s[0] != s[3] || s[0] == s[1] {
+		t.Fatal(s)
+	}
+
+	for i, w := range Enumerate(NGrams(Of(1, 2, 3, 1, 2, 3), 3)) {
+		h, _ := Drain(RollingHashes(slices.Values(w), 3, hash))
+		if h != s[i] {
+			t.Fatal(i, h, s[i])
+		}
+	}
+}
@@ -33,6 +33,20 @@ type Addable interface {
 	int | int8 | int16 | int32 | int64 | uint | uint8 | uint16 | uint32 | uint64 | uintptr | float32 | float64 | complex64 | complex128 | string
 }
 
+// Integer is a constraint that matches all of the built-in integer
+// types.
+type Integer interface {
+	int | int8 | int16 | int32 | int64 | uint | uint8 | uint16 | uint32 | uint64 | uintptr
+}
+
+// Real is a constraint that matches all of the built-in integer and
+// floating-point types. Unlike [cmp.Ordered], it does not match
+// strings, so its values can be both summed and compared
+// numerically.
+type Real interface {
+	Integer | float32 | float64
+}
+
 type Multiplyable interface {
 	int | int8 | int16 | int32 | int64 | uint | uint8 | uint16 | uint32 | uint64 | uintptr | float32 | float64 | complex64 | complex128
 }